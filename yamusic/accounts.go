@@ -0,0 +1,170 @@
+package yamusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrUnknownProfile is returned for account profile missing in config
+var ErrUnknownProfile = ClientError("unknown account profile")
+
+// copyBatchSize is max number of tracks added to playlist by one request
+// while copying
+const copyBatchSize = 200
+
+type (
+	// AccountProfile is a named account in config
+	//
+	//	accounts:
+	//	  family:
+	//	    token: ...
+	//	  work:
+	//	    token: ...
+	//	    uid: 42
+	AccountProfile struct {
+		Token string `yaml:"token"`
+		// UserID is discovered with AccountService.GetStatus if it's zero
+		UserID int `yaml:"uid"`
+	}
+	// profileClients are clients of account profiles by name
+	profileClients struct {
+		mu      sync.Mutex
+		clients map[string]*Client
+	}
+)
+
+// AccountProfiles adds named account profiles to the ones from config
+func AccountProfiles(profiles map[string]AccountProfile) func(*Client) {
+	return func(c *Client) {
+		if c.config.Accounts == nil {
+			c.config.Accounts = make(map[string]AccountProfile, len(profiles))
+		}
+		for name, profile := range profiles {
+			c.config.Accounts[name] = profile
+		}
+	}
+}
+
+// Profiles returns sorted names of account profiles
+func (c *Client) Profiles() []string {
+	names := make([]string, 0, len(c.config.Accounts))
+	for name := range c.config.Accounts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Profile returns client of account profile by name. Clients of profiles
+// are created once and share transport, cache, rate limiter, retries,
+// logger and telemetry of c.
+func (c *Client) Profile(ctx context.Context, name string) (*Client, error) {
+	c.profiles.mu.Lock()
+	client, ok := c.profiles.clients[name]
+	c.profiles.mu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	profile, ok := c.config.Accounts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownProfile, name)
+	}
+
+	client = c.WithToken(profile.Token, profile.UserID)
+	client.logger = client.logger.With("account", name)
+	if err := client.Authorize(ctx); err != nil {
+		return nil, fmt.Errorf("account %q: %w", name, err)
+	}
+
+	c.profiles.mu.Lock()
+	defer c.profiles.mu.Unlock()
+	// Client could be created concurrently while this one was authorized
+	if created, ok := c.profiles.clients[name]; ok {
+		return created, nil
+	}
+	if c.profiles.clients == nil {
+		c.profiles.clients = make(map[string]*Client)
+	}
+	c.profiles.clients[name] = client
+	return client, nil
+}
+
+// WithToken returns client of another account authorized by token. It shares
+// transport, cache, rate limiter, retries, logger and telemetry of c.
+// Authenticator of c isn't used by the returned client.
+func (c *Client) WithToken(token string, userID int) *Client {
+	client := *c
+	client.client = c.transport
+	client.accessToken = token
+	client.userID = userID
+	client.config.Token = token
+	client.auth = nil
+	client.profiles = new(profileClients)
+	client.initServices()
+	return &client
+}
+
+// Copy copies playlist of user by kind into a new playlist of another
+// account with the same title and visibility. It returns the new playlist.
+// Tracks that can't be added to playlists are skipped and reported
+// in the returned error.
+func (s *PlaylistsService) Copy(
+	ctx context.Context,
+	userID int,
+	kind int,
+	dst *Client,
+) (*PlaylistsResult, error) {
+	src, _, err := s.Get(BypassCache(ctx), userID, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []PlaylistsTrack
+	var errs []error
+	for _, track := range src.Result.Tracks {
+		playlistsTrack, err := newPlaylistsTrack(track.Track)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tracks = append(tracks, playlistsTrack)
+	}
+
+	created, _, err := dst.Playlists().Create(
+		ctx,
+		src.Result.Title,
+		src.Result.Visibility == "public",
+	)
+	if err != nil {
+		return nil, err
+	}
+	playlist := created.Result
+
+	for start := 0; start < len(tracks); start += copyBatchSize {
+		batch := tracks[start:min(start+copyBatchSize, len(tracks))]
+		added, _, err := dst.Playlists().AddTracks(
+			ctx,
+			playlist.Kind,
+			playlist.Revision,
+			batch,
+			&PlaylistsAddTracksOptions{At: start},
+		)
+		if err != nil {
+			return &playlist, errors.Join(append(errs, err)...)
+		}
+		playlist = added.Result
+	}
+
+	s.client.logger.Info("playlist copied",
+		"playlist_kind", kind,
+		"dst_uid", dst.UserID(),
+		"dst_playlist_kind", playlist.Kind,
+		"tracks", len(tracks),
+	)
+
+	return &playlist, errors.Join(errs...)
+}
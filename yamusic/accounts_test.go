@@ -0,0 +1,120 @@
+package yamusic_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"awesome/yamusic"
+	"awesome/yamusic/yamusictest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingDoer counts requests sent through it
+type countingDoer struct {
+	doer     yamusic.Doer
+	requests atomic.Int32
+}
+
+func (d *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	d.requests.Add(1)
+	return d.doer.Do(req)
+}
+
+func setupAccounts(t *testing.T) (*yamusictest.Server, *countingDoer, *yamusic.Client) {
+	server := yamusictest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddUser(42, "family", "family-token")
+	server.AddUser(7, "work", "work-token")
+	for i := 1; i <= 3; i++ {
+		server.AddTrack(yamusic.Track{
+			ID:      fmt.Sprint(i),
+			Title:   fmt.Sprintf("Track %d", i),
+			Artists: yamusic.Artists{{ID: i, Name: "Artist"}},
+			Albums:  yamusic.Albums{{ID: i * 10}},
+		}, nil)
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := fmt.Sprintf(`output: %s
+log: %s
+accounts:
+  family:
+    token: family-token
+`, dir, dir)
+	assert.NoError(t, os.WriteFile(configPath, []byte(config), 0o644))
+
+	doer := &countingDoer{doer: server.Client()}
+	baseURL, _ := url.Parse(server.URL + "/")
+	client := yamusic.NewClient(
+		yamusic.NewConfig(configPath),
+		yamusic.AccountProfiles(map[string]yamusic.AccountProfile{
+			"work": {Token: "work-token", UserID: 7},
+		}),
+		yamusic.BaseURL(baseURL),
+		yamusic.HTTPClient(doer),
+	)
+
+	return server, doer, client
+}
+
+func TestClient_Profile(t *testing.T) {
+	_, doer, client := setupAccounts(t)
+	ctx := context.Background()
+
+	assert.Equal(t, []string{"family", "work"}, client.Profiles())
+
+	family, err := client.Profile(ctx, "family")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, family.UserID())
+
+	again, err := client.Profile(ctx, "family")
+	assert.NoError(t, err)
+	assert.Same(t, family, again)
+
+	work, err := client.Profile(ctx, "work")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, work.UserID())
+
+	_, _, err = work.Playlists().List(ctx, 0)
+	assert.NoError(t, err)
+	// Status of family and playlists of work went through the same transport
+	assert.Equal(t, int32(2), doer.requests.Load())
+
+	_, err = client.Profile(ctx, "school")
+	assert.ErrorIs(t, err, yamusic.ErrUnknownProfile)
+}
+
+func TestPlaylistsService_Copy(t *testing.T) {
+	server, _, client := setupAccounts(t)
+	ctx := context.Background()
+	kind := server.AddPlaylist(42, "Road trip", "3", "1", "2")
+
+	family, err := client.Profile(ctx, "family")
+	assert.NoError(t, err)
+	work, err := client.Profile(ctx, "work")
+	assert.NoError(t, err)
+
+	copied, err := family.Playlists().Copy(ctx, 0, kind, work)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, copied.UID)
+	assert.Equal(t, "Road trip", copied.Title)
+
+	got, ok := server.Playlist(7, copied.Kind)
+	if assert.True(t, ok) && assert.Len(t, got.Tracks, 3) {
+		var ids []string
+		for _, track := range got.Tracks {
+			ids = append(ids, track.Track.ID)
+		}
+		assert.Equal(t, []string{"3", "1", "2"}, ids)
+	}
+}
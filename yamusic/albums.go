@@ -0,0 +1,150 @@
+package yamusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type (
+	// AlbumsService is a service to deal with albums
+	AlbumsService struct {
+		client *Client
+	}
+	// AlbumResp describes get album response
+	AlbumResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         Album          `json:"result"`
+	}
+	// AlbumsResp describes get several albums response
+	AlbumsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         Albums         `json:"result"`
+	}
+	// AlbumWithTracksResp describes get album with tracks response
+	AlbumWithTracksResp struct {
+		InvocationInfo InvocationInfo  `json:"invocationInfo"`
+		Error          Error           `json:"error"`
+		Result         AlbumWithTracks `json:"result"`
+	}
+	// AlbumWithTracks is album with its tracks split by volumes (discs)
+	AlbumWithTracks struct {
+		Album
+		Volumes [][]Track `json:"volumes"`
+	}
+)
+
+// Tracks returns tracks of all volumes in order
+func (a *AlbumWithTracks) Tracks() []Track {
+	var tracks []Track
+	for _, volume := range a.Volumes {
+		tracks = append(tracks, volume...)
+	}
+	return tracks
+}
+
+// GetOne returns album by its ID
+func (s *AlbumsService) GetOne(ctx context.Context, id int) (*AlbumResp, *http.Response, error) {
+	uri := fmt.Sprintf("albums/%v", id)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	album := new(AlbumResp)
+	resp, err := s.client.Do(ctx, req, album)
+	return album, resp, err
+}
+
+// GetAll returns albums by their IDs
+func (s *AlbumsService) GetAll(ctx context.Context, ids []int) (*AlbumsResp, *http.Response, error) {
+	stringIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		stringIDs = append(stringIDs, strconv.Itoa(id))
+	}
+
+	form := url.Values{}
+	form.Set("album-ids", strings.Join(stringIDs, ","))
+
+	req, err := s.client.NewRequest(http.MethodPost, "albums", form)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	albums := new(AlbumsResp)
+	resp, err := s.client.Do(withRetrySafe(ctx), req, albums)
+	return albums, resp, err
+}
+
+// GetWithTracks returns album by its ID with tracks split by volumes.
+// Position of track in album is in its Albums[0].TrackPosition.
+func (s *AlbumsService) GetWithTracks(
+	ctx context.Context,
+	id int,
+) (*AlbumWithTracksResp, *http.Response, error) {
+	uri := fmt.Sprintf("albums/%v/with-tracks", id)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	album := new(AlbumWithTracksResp)
+	resp, err := s.client.Do(ctx, req, album)
+	return album, resp, err
+}
+
+// DownloadOne downloads tracks of album by ID into a folder named
+// by album's artist and title under the output directory from config
+func (s *AlbumsService) DownloadOne(ctx context.Context, id int) (*DownloadResult, error) {
+	resp, _, err := s.GetWithTracks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	album := resp.Result
+
+	tracks := album.Tracks()
+	s.client.logger.Info("album to download",
+		"album_id", id, "title", album.Title, "tracks", len(tracks))
+	if len(tracks) == 0 {
+		return new(DownloadResult), nil
+	}
+
+	album_folder := s.client.config.Output + "/" + albumFolderName(album.Album)
+
+	return s.client.tracks.DownloadAll(ctx, tracks, album_folder)
+}
+
+// DownloadAll downloads albums by IDs. Failed albums and tracks don't stop
+// the download, their errors are joined into the returned error.
+func (s *AlbumsService) DownloadAll(ctx context.Context, ids []int) (*DownloadResult, error) {
+	result := new(DownloadResult)
+	var errs []error
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return result, errors.Join(append(errs, err)...)
+		}
+
+		downloaded, err := s.DownloadOne(ctx, id)
+		result.add(downloaded)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("album %d: %w", id, err))
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// albumFolderName returns name of folder for album's tracks
+func albumFolderName(album Album) string {
+	name := album.Title
+	if len(album.Artists) > 0 {
+		name = album.Artists[0].Name + " - " + name
+	}
+	return strings.ReplaceAll(name, "/", "|")
+}
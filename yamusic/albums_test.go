@@ -0,0 +1,86 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumsService_GetOne(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/albums/3389008", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"result":{"id":3389008,"title":"The 2nd Law","trackCount":13}}`)
+	})
+
+	result, _, err := client.Albums().GetOne(context.Background(), 3389008)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "The 2nd Law", result.Result.Title)
+	assert.Equal(t, 13, result.Result.TrackCount)
+}
+
+func TestAlbumsService_GetAll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/albums", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "1,2", r.FormValue("album-ids"))
+		fmt.Fprint(w, `{"result":[{"id":1},{"id":2}]}`)
+	})
+
+	result, _, err := client.Albums().GetAll(context.Background(), []int{1, 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Result, 2)
+}
+
+func TestAlbumsService_GetWithTracks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/albums/1/with-tracks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"id":1,"title":"Double","volumes":[
+			[{"id":"11","albums":[{"id":1,"trackPosition":{"volume":1,"index":1}}]}],
+			[{"id":"21","albums":[{"id":1,"trackPosition":{"volume":2,"index":1}}]},{"id":"22"}]
+		]}}`)
+	})
+
+	result, _, err := client.Albums().GetWithTracks(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Result.Volumes, 2)
+	assert.Equal(t, 2, result.Result.Volumes[1][0].Albums[0].TrackPosition.Volume)
+
+	var ids []string
+	for _, track := range result.Result.Tracks() {
+		ids = append(ids, track.ID)
+	}
+	assert.Equal(t, []string{"11", "21", "22"}, ids)
+}
+
+func TestAlbumsService_DownloadAll(t *testing.T) {
+	setup(func(c *Client) { c.config.Output = t.TempDir() })
+	defer teardown()
+
+	mux.HandleFunc("/albums/1/with-tracks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"id":1,"title":"Empty","volumes":[]}}`)
+	})
+	mux.HandleFunc("/albums/2/with-tracks", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	result, err := client.Albums().DownloadAll(context.Background(), []int{1, 2})
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "album 2")
+	assert.Equal(t, 0, result.Downloaded)
+}
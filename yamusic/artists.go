@@ -0,0 +1,237 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type (
+	// ArtistsService is a service to deal with artists
+	ArtistsService struct {
+		client *Client
+	}
+	// Pager describes page of paginated response
+	Pager struct {
+		Total   int `json:"total"`
+		Page    int `json:"page"`
+		PerPage int `json:"perPage"`
+	}
+	// ArtistBriefInfoResp describes get artist's brief info response
+	ArtistBriefInfoResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Artist         Artist  `json:"artist"`
+			Albums         Albums  `json:"albums"`
+			AlsoAlbums     Albums  `json:"alsoAlbums"`
+			PopularTracks  []Track `json:"popularTracks"`
+			SimilarArtists Artists `json:"similarArtists"`
+			LastReleaseIDs []int   `json:"lastReleaseIds"`
+		} `json:"result"`
+	}
+	// ArtistTracksResp describes get artist's tracks response
+	ArtistTracksResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Pager  Pager   `json:"pager"`
+			Tracks []Track `json:"tracks"`
+		} `json:"result"`
+	}
+	// ArtistAlbumsResp describes get artist's direct or also albums response
+	ArtistAlbumsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Pager  Pager  `json:"pager"`
+			Albums Albums `json:"albums"`
+		} `json:"result"`
+	}
+	// ArtistSimilarResp describes get similar artists response
+	ArtistSimilarResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Artist         Artist  `json:"artist"`
+			SimilarArtists Artists `json:"similarArtists"`
+		} `json:"result"`
+	}
+	// ArtistTrackIDsResp describes get artist's track ids by rating response
+	ArtistTrackIDsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Artist Artist   `json:"artist"`
+			Tracks []string `json:"tracks"`
+		} `json:"result"`
+	}
+)
+
+type (
+	// ArtistsPageOptions are options of paginated methods
+	ArtistsPageOptions struct {
+		Page int
+		// PageSize is number of items on page. Default is 20.
+		PageSize int
+	}
+	// ArtistsAlbumsOptions are options of methods returning albums
+	ArtistsAlbumsOptions struct {
+		Page int
+		// PageSize is number of albums on page. Default is 20.
+		PageSize int
+		// SortBy is "year" or "rating". Default is "year".
+		SortBy string
+	}
+)
+
+// GetBriefInfo returns artist with their albums, popular tracks
+// and similar artists
+func (s *ArtistsService) GetBriefInfo(
+	ctx context.Context,
+	id int,
+) (*ArtistBriefInfoResp, *http.Response, error) {
+	uri := fmt.Sprintf("artists/%v/brief-info", id)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := new(ArtistBriefInfoResp)
+	resp, err := s.client.Do(ctx, req, info)
+	return info, resp, err
+}
+
+// GetTracks returns page of artist's tracks
+func (s *ArtistsService) GetTracks(
+	ctx context.Context,
+	id int,
+	opts *ArtistsPageOptions,
+) (*ArtistTracksResp, *http.Response, error) {
+	if opts == nil {
+		opts = &ArtistsPageOptions{}
+	}
+
+	uri := fmt.Sprintf("artists/%v/tracks?%v", id, pageParams(opts.Page, opts.PageSize).Encode())
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracks := new(ArtistTracksResp)
+	resp, err := s.client.Do(ctx, req, tracks)
+	return tracks, resp, err
+}
+
+// GetDirectAlbums returns page of albums released by artist
+func (s *ArtistsService) GetDirectAlbums(
+	ctx context.Context,
+	id int,
+	opts *ArtistsAlbumsOptions,
+) (*ArtistAlbumsResp, *http.Response, error) {
+	return s.getAlbums(ctx, fmt.Sprintf("artists/%v/direct-albums", id), opts)
+}
+
+// GetAlsoAlbums returns page of albums artist took part in,
+// e.g. compilations
+func (s *ArtistsService) GetAlsoAlbums(
+	ctx context.Context,
+	id int,
+	opts *ArtistsAlbumsOptions,
+) (*ArtistAlbumsResp, *http.Response, error) {
+	return s.getAlbums(ctx, fmt.Sprintf("artists/%v/also-albums", id), opts)
+}
+
+// GetSimilar returns artists similar to artist
+func (s *ArtistsService) GetSimilar(
+	ctx context.Context,
+	id int,
+) (*ArtistSimilarResp, *http.Response, error) {
+	uri := fmt.Sprintf("artists/%v/similar", id)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	similar := new(ArtistSimilarResp)
+	resp, err := s.client.Do(ctx, req, similar)
+	return similar, resp, err
+}
+
+// GetTrackIDsByRating returns ids of artist's tracks from the most
+// popular one
+func (s *ArtistsService) GetTrackIDsByRating(
+	ctx context.Context,
+	id int,
+) (*ArtistTrackIDsResp, *http.Response, error) {
+	uri := fmt.Sprintf("artists/%v/track-ids-by-rating", id)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := new(ArtistTrackIDsResp)
+	resp, err := s.client.Do(ctx, req, ids)
+	return ids, resp, err
+}
+
+// GetPopularTracks returns up to limit of artist's most popular tracks.
+// All tracks are returned if limit is zero.
+func (s *ArtistsService) GetPopularTracks(ctx context.Context, id int, limit int) ([]Track, error) {
+	ids, _, err := s.GetTrackIDsByRating(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	trackIDs := ids.Result.Tracks
+	if limit > 0 && len(trackIDs) > limit {
+		trackIDs = trackIDs[:limit]
+	}
+	if len(trackIDs) == 0 {
+		return nil, nil
+	}
+
+	tracks, err := s.client.tracks.GetAll(ctx, trackIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tracks.Tracks, nil
+}
+
+func (s *ArtistsService) getAlbums(
+	ctx context.Context,
+	uri string,
+	opts *ArtistsAlbumsOptions,
+) (*ArtistAlbumsResp, *http.Response, error) {
+	if opts == nil {
+		opts = &ArtistsAlbumsOptions{}
+	}
+
+	queryParams := pageParams(opts.Page, opts.PageSize)
+	if opts.SortBy != "" {
+		queryParams.Set("sort-by", opts.SortBy)
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, uri+"?"+queryParams.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	albums := new(ArtistAlbumsResp)
+	resp, err := s.client.Do(ctx, req, albums)
+	return albums, resp, err
+}
+
+// pageParams returns query params of page
+func pageParams(page, pageSize int) url.Values {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("page", strconv.Itoa(page))
+	queryParams.Set("page-size", strconv.Itoa(pageSize))
+	return queryParams
+}
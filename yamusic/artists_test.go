@@ -0,0 +1,109 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtistsService_GetBriefInfo(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/artists/1/brief-info", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"result":{
+			"artist":{"id":1,"name":"Muse"},
+			"albums":[{"id":10}],
+			"popularTracks":[{"id":"100"}],
+			"similarArtists":[{"id":2,"name":"Radiohead"}]
+		}}`)
+	})
+
+	result, _, err := client.Artists().GetBriefInfo(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse", result.Result.Artist.Name)
+	assert.Equal(t, 10, result.Result.Albums[0].ID)
+	assert.Equal(t, "100", result.Result.PopularTracks[0].ID)
+	assert.Equal(t, "Radiohead", result.Result.SimilarArtists[0].Name)
+}
+
+func TestArtistsService_GetTracks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/artists/1/tracks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		assert.Equal(t, "20", r.URL.Query().Get("page-size"))
+		fmt.Fprint(w, `{"result":{"pager":{"total":45,"page":2,"perPage":20},"tracks":[{"id":"1"}]}}`)
+	})
+
+	result, _, err := client.Artists().GetTracks(context.Background(), 1, &ArtistsPageOptions{Page: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, Pager{Total: 45, Page: 2, PerPage: 20}, result.Result.Pager)
+	assert.Len(t, result.Result.Tracks, 1)
+}
+
+func TestArtistsService_GetAlbums(t *testing.T) {
+	setup()
+	defer teardown()
+
+	for _, path := range []string{"direct-albums", "also-albums"} {
+		mux.HandleFunc("/artists/1/"+path, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "rating", r.URL.Query().Get("sort-by"))
+			assert.Equal(t, "5", r.URL.Query().Get("page-size"))
+			fmt.Fprintf(w, `{"result":{"pager":{"total":1},"albums":[{"id":1,"title":%q}]}}`, path)
+		})
+	}
+
+	opts := &ArtistsAlbumsOptions{PageSize: 5, SortBy: "rating"}
+	direct, _, err := client.Artists().GetDirectAlbums(context.Background(), 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "direct-albums", direct.Result.Albums[0].Title)
+
+	also, _, err := client.Artists().GetAlsoAlbums(context.Background(), 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "also-albums", also.Result.Albums[0].Title)
+}
+
+func TestArtistsService_GetSimilar(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/artists/1/similar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"artist":{"id":1},"similarArtists":[{"id":2},{"id":3}]}}`)
+	})
+
+	result, _, err := client.Artists().GetSimilar(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Result.SimilarArtists, 2)
+}
+
+func TestArtistsService_GetPopularTracks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/artists/1/track-ids-by-rating", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"artist":{"id":1},"tracks":["3","1","2"]}}`)
+	})
+	mux.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "3,1", r.FormValue("track-ids"))
+		fmt.Fprint(w, `{"result":[{"id":"1"},{"id":"3"}]}`)
+	})
+
+	tracks, err := client.Artists().GetPopularTracks(context.Background(), 1, 2)
+
+	assert.NoError(t, err)
+	if assert.Len(t, tracks, 2) {
+		assert.Equal(t, "3", tracks[0].ID)
+		assert.Equal(t, "1", tracks[1].ID)
+	}
+}
@@ -0,0 +1,557 @@
+package yamusic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	oauthURL = "https://oauth.yandex.ru"
+	// tokenLeeway is how long before expiry token is considered expired
+	tokenLeeway = time.Minute
+)
+
+type (
+	// Token is an OAuth token of Yandex.Music user
+	Token struct {
+		AccessToken  string    `json:"access_token"`
+		RefreshToken string    `json:"refresh_token,omitempty"`
+		TokenType    string    `json:"token_type,omitempty"`
+		Expiry       time.Time `json:"expiry,omitempty"`
+		UserID       int       `json:"uid,omitempty"`
+	}
+	// TokenStore keeps token between runs
+	TokenStore interface {
+		// Load returns saved token or nil if there is no one
+		Load(ctx context.Context) (*Token, error)
+		// Save saves token replacing the previous one
+		Save(ctx context.Context, token *Token) error
+	}
+	// AuthOptions configures Authenticator
+	AuthOptions struct {
+		// ClientID and ClientSecret of OAuth application
+		ClientID     string
+		ClientSecret string
+		// OAuthURL is base URL of OAuth server. Default is https://oauth.yandex.ru
+		OAuthURL *url.URL
+		// HTTPClient is used to communicate with OAuth server.
+		// Default is http.DefaultClient.
+		HTTPClient Doer
+		// Store keeps token between runs. Default is in-memory store.
+		Store TokenStore
+		// Relogin is called when API answers unauthorized and token can't be
+		// refreshed, e.g. to run DeviceLogin again. If nil, ErrUnauthorized
+		// is returned in that case.
+		Relogin func(ctx context.Context, a *Authenticator) (*Token, error)
+	}
+	// Authenticator obtains, stores and refreshes OAuth token
+	Authenticator struct {
+		opts AuthOptions
+
+		mu    sync.Mutex
+		token *Token
+		// renewMu serializes renewals, so a refresh token is spent once
+		renewMu sync.Mutex
+	}
+	// DeviceCode is a code user enters on VerificationURL to authorize
+	// the device
+	DeviceCode struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURL string `json:"verification_url"`
+		// Interval is how often in seconds token can be polled
+		Interval int `json:"interval"`
+		// ExpiresIn is how long in seconds the code is valid
+		ExpiresIn int `json:"expires_in"`
+	}
+	// OAuthError is an error answered by OAuth server
+	OAuthError struct {
+		StatusCode  int
+		Code        string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	// MemoryTokenStore keeps token in memory
+	MemoryTokenStore struct {
+		mu    sync.Mutex
+		token *Token
+	}
+	// FileTokenStore keeps token in JSON file
+	FileTokenStore struct {
+		path string
+	}
+	// AuthDoer is a Doer that authorizes API requests with token
+	// of Authenticator and renews it when API answers unauthorized
+	AuthDoer struct {
+		doer Doer
+		auth *Authenticator
+	}
+	// tokenResp describes token method response of OAuth server
+	tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int    `json:"expires_in"`
+		UID          int    `json:"uid"`
+	}
+)
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("yamusic: oauth %d %s: %s", e.StatusCode, e.Code, e.Description)
+	}
+	return fmt.Sprintf("yamusic: oauth %d %s", e.StatusCode, e.Code)
+}
+
+// Unwrap returns ErrUnauthorized if credentials or token were rejected
+func (e *OAuthError) Unwrap() error {
+	switch e.Code {
+	case "invalid_grant", "invalid_client", "unauthorized_client", "expired_token":
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// Valid reports whether token is set and isn't expired
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" &&
+		(t.Expiry.IsZero() || time.Now().Add(tokenLeeway).Before(t.Expiry))
+}
+
+// NewAuthenticator returns a new Authenticator.
+// If opts is nil, default options are used.
+func NewAuthenticator(opts *AuthOptions) *Authenticator {
+	if opts == nil {
+		opts = &AuthOptions{}
+	}
+
+	a := &Authenticator{opts: *opts}
+	if a.opts.OAuthURL == nil {
+		a.opts.OAuthURL, _ = url.Parse(oauthURL)
+	}
+	if a.opts.HTTPClient == nil {
+		a.opts.HTTPClient = http.DefaultClient
+	}
+	if a.opts.Store == nil {
+		a.opts.Store = new(MemoryTokenStore)
+	}
+
+	return a
+}
+
+// Auth sets Authenticator for Yandex.Music client. Its token replaces
+// the one from config and is renewed when API answers unauthorized.
+func Auth(auth *Authenticator) func(*Client) {
+	return func(c *Client) {
+		if auth != nil {
+			c.auth = auth
+		}
+	}
+}
+
+// PasswordLogin obtains token by username and password of user
+func (a *Authenticator) PasswordLogin(
+	ctx context.Context,
+	username string,
+	password string,
+) (*Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "password")
+	form.Set("username", username)
+	form.Set("password", password)
+
+	return a.requestToken(ctx, form)
+}
+
+// RequestDeviceCode starts device authorization. User must enter UserCode
+// of returned DeviceCode on VerificationURL.
+func (a *Authenticator) RequestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{}
+	form.Set("client_id", a.opts.ClientID)
+
+	code := new(DeviceCode)
+	if err := a.post(ctx, "device/code", form, code); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// PollDeviceToken waits until user authorizes the device by code
+// and returns obtained token
+func (a *Authenticator) PollDeviceToken(ctx context.Context, code *DeviceCode) (*Token, error) {
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "device_code")
+	form.Set("code", code.DeviceCode)
+
+	for {
+		token, err := a.requestToken(ctx, form)
+		oauthErr, ok := err.(*OAuthError)
+		switch {
+		case !ok:
+			return token, err
+		case oauthErr.Code == "slow_down":
+			interval *= 2
+		case oauthErr.Code != "authorization_pending":
+			return nil, err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// DeviceLogin obtains token with device authorization. prompt is called
+// with the code that must be shown to user.
+func (a *Authenticator) DeviceLogin(
+	ctx context.Context,
+	prompt func(code *DeviceCode),
+) (*Token, error) {
+	code, err := a.RequestDeviceCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt(code)
+
+	return a.PollDeviceToken(ctx, code)
+}
+
+// Refresh obtains a new token with refresh token of the current one
+func (a *Authenticator) Refresh(ctx context.Context) (*Token, error) {
+	a.renewMu.Lock()
+	defer a.renewMu.Unlock()
+
+	return a.refresh(ctx)
+}
+
+// Token returns valid token. Expired token is renewed.
+func (a *Authenticator) Token(ctx context.Context) (*Token, error) {
+	token, err := a.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token.Valid() {
+		return token, nil
+	}
+	return a.renew(ctx, token)
+}
+
+// SetToken makes token the current one and saves it to the store
+func (a *Authenticator) SetToken(ctx context.Context, token *Token) error {
+	a.mu.Lock()
+	a.token = token
+	a.mu.Unlock()
+
+	return a.opts.Store.Save(ctx, token)
+}
+
+// current returns current token loading it from the store if needed
+func (a *Authenticator) current(ctx context.Context) (*Token, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != nil {
+		return a.token, nil
+	}
+
+	token, err := a.opts.Store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.token = token
+	return token, nil
+}
+
+// renew replaces stale token with refreshed one or with token of
+// a new login. If token was already replaced by concurrent call,
+// the new one is returned. Relogin is called under the renewal lock,
+// so it must not call Refresh.
+func (a *Authenticator) renew(ctx context.Context, stale *Token) (*Token, error) {
+	a.renewMu.Lock()
+	defer a.renewMu.Unlock()
+
+	current, err := a.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current != stale && current.Valid() {
+		return current, nil
+	}
+
+	if current != nil && current.RefreshToken != "" {
+		token, err := a.refresh(ctx)
+		if err == nil || a.opts.Relogin == nil {
+			return token, err
+		}
+	}
+
+	if a.opts.Relogin == nil {
+		return nil, ErrUnauthorized
+	}
+
+	token, err := a.opts.Relogin(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return token, a.SetToken(ctx, token)
+}
+
+// refresh obtains a new token with refresh token of the current one.
+// The caller must hold renewMu.
+func (a *Authenticator) refresh(ctx context.Context) (*Token, error) {
+	current, err := a.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.RefreshToken == "" {
+		return nil, ErrUnauthorized
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", current.RefreshToken)
+
+	token, err := a.fetchToken(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh token is not always rotated
+	if token.RefreshToken == "" {
+		token.RefreshToken = current.RefreshToken
+	}
+	if token.UserID == 0 {
+		token.UserID = current.UserID
+	}
+	return token, a.SetToken(ctx, token)
+}
+
+// requestToken requests token method of OAuth server with form and saves
+// obtained token
+func (a *Authenticator) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	token, err := a.fetchToken(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	return token, a.SetToken(ctx, token)
+}
+
+// fetchToken requests token method of OAuth server with form
+func (a *Authenticator) fetchToken(ctx context.Context, form url.Values) (*Token, error) {
+	form.Set("client_id", a.opts.ClientID)
+	form.Set("client_secret", a.opts.ClientSecret)
+
+	resp := new(tokenResp)
+	if err := a.post(ctx, "token", form, resp); err != nil {
+		return nil, err
+	}
+
+	token := &Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		TokenType:    resp.TokenType,
+		UserID:       resp.UID,
+	}
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// post sends form to OAuth server and decodes JSON response into v
+func (a *Authenticator) post(ctx context.Context, uri string, form url.Values, v interface{}) error {
+	u := a.opts.OAuthURL.ResolveReference(&url.URL{Path: uri})
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		u.String(),
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dat, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		oauthErr := &OAuthError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(dat, oauthErr); err != nil || oauthErr.Code == "" {
+			oauthErr.Code = http.StatusText(resp.StatusCode)
+		}
+		return oauthErr
+	}
+
+	return json.Unmarshal(dat, v)
+}
+
+// NewAuthDoer returns a Doer that authorizes requests made by doer
+// with token of auth
+func NewAuthDoer(doer Doer, auth *Authenticator) *AuthDoer {
+	return &AuthDoer{doer: doer, auth: auth}
+}
+
+// Do sets token to the request and sends it. If API answers unauthorized,
+// token is renewed and the request is sent once again.
+func (d *AuthDoer) Do(req *http.Request) (*http.Response, error) {
+	// Only requests made by Client.NewRequest are authorized,
+	// so token never leaks to storage hosts
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "OAuth") {
+		return d.doer.Do(req)
+	}
+
+	ctx := req.Context()
+
+	token, err := d.auth.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.doer.Do(authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	renewed, err := d.auth.renew(ctx, token)
+	if err != nil {
+		// Let caller get *APIError from the original response
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry := req.Clone(ctx)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return d.doer.Do(authorize(retry, renewed))
+}
+
+// authorize returns copy of request with Authorization header of token
+func authorize(req *http.Request, token *Token) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "OAuth "+token.AccessToken)
+	return req
+}
+
+// Authorize makes sure client is ready to call methods of user: obtains
+// token if Authenticator is set and discovers user's id with
+// AccountService.GetStatus if it wasn't provided.
+func (c *Client) Authorize(ctx context.Context) error {
+	if c.auth != nil {
+		token, err := c.auth.Token(ctx)
+		if err != nil {
+			return err
+		}
+		if c.userID == 0 {
+			c.userID = token.UserID
+		}
+	}
+
+	if c.userID != 0 {
+		return nil
+	}
+
+	status, _, err := c.Account().GetStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Result.Account.UID == 0 {
+		return ErrUnauthorized
+	}
+
+	c.userID = status.Result.Account.UID
+	return nil
+}
+
+// Load returns token kept in memory
+func (s *MemoryTokenStore) Load(context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token, nil
+}
+
+// Save keeps token in memory
+func (s *MemoryTokenStore) Save(_ context.Context, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	return nil
+}
+
+// NewFileTokenStore returns store keeping token in JSON file by path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Load reads token from file. It returns nil if file doesn't exist.
+func (s *FileTokenStore) Load(context.Context) (*Token, error) {
+	b, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	token := new(Token)
+	if err := json.Unmarshal(b, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Save writes token to file readable only by its owner
+func (s *FileTokenStore) Save(_ context.Context, token *Token) error {
+	b, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, bytes.TrimSpace(b), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,263 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOAuth is a local OAuth server issuing sequential tokens
+type fakeOAuth struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	issued  int
+	pending int
+}
+
+func newFakeOAuth(t *testing.T) *fakeOAuth {
+	f := new(fakeOAuth)
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "clientID", r.FormValue("client_id"))
+		assert.Equal(t, "clientSecret", r.FormValue("client_secret"))
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch r.FormValue("grant_type") {
+		case "password":
+			if r.FormValue("password") != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant","error_description":"login or password is not valid"}`)
+				return
+			}
+		case "device_code":
+			if f.pending > 0 {
+				f.pending--
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"authorization_pending"}`)
+				return
+			}
+			assert.Equal(t, "deviceCode", r.FormValue("code"))
+		case "refresh_token":
+			assert.Equal(t, fmt.Sprintf("refresh%d", f.issued), r.FormValue("refresh_token"))
+		}
+
+		f.issued++
+		fmt.Fprintf(
+			w,
+			`{"access_token":"token%d","refresh_token":"refresh%d","token_type":"bearer","expires_in":3600,"uid":%d}`,
+			f.issued, f.issued, userID,
+		)
+	})
+	mux.HandleFunc("/device/code", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"device_code":"deviceCode","user_code":"USERCODE","verification_url":"https://ya.ru/device","interval":0,"expires_in":300}`)
+	})
+
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+func (f *fakeOAuth) authenticator(store TokenStore) *Authenticator {
+	u, _ := url.Parse(f.URL)
+	return NewAuthenticator(&AuthOptions{
+		ClientID:     "clientID",
+		ClientSecret: "clientSecret",
+		OAuthURL:     u,
+		Store:        store,
+	})
+}
+
+func TestAuthenticator_PasswordLogin(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	defer oauth.Close()
+
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+	auth := oauth.authenticator(store)
+
+	_, err := auth.PasswordLogin(context.Background(), "user", "wrong")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	token, err := auth.PasswordLogin(context.Background(), "user", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "token1", token.AccessToken)
+	assert.Equal(t, userID, token.UserID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+
+	saved, err := store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, token.AccessToken, saved.AccessToken)
+	assert.Equal(t, token.RefreshToken, saved.RefreshToken)
+	assert.True(t, token.Expiry.Equal(saved.Expiry))
+}
+
+func TestAuthenticator_DeviceLogin(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	defer oauth.Close()
+	oauth.pending = 1
+
+	auth := oauth.authenticator(nil)
+
+	var prompted *DeviceCode
+	token, err := auth.DeviceLogin(context.Background(), func(code *DeviceCode) {
+		prompted = code
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "USERCODE", prompted.UserCode)
+	assert.Equal(t, "token1", token.AccessToken)
+	assert.Equal(t, 0, oauth.pending)
+}
+
+func TestAuthenticator_RefreshesExpiredToken(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	defer oauth.Close()
+	oauth.issued = 1
+
+	store := new(MemoryTokenStore)
+	assert.NoError(t, store.Save(context.Background(), &Token{
+		AccessToken:  "token1",
+		RefreshToken: "refresh1",
+		Expiry:       time.Now().Add(-time.Hour),
+	}))
+
+	token, err := oauth.authenticator(store).Token(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "token2", token.AccessToken)
+}
+
+func TestAuthDoer_RenewsTokenOnUnauthorized(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	defer oauth.Close()
+
+	auth := oauth.authenticator(nil)
+	_, err := auth.PasswordLogin(context.Background(), "user", "secret")
+	assert.NoError(t, err)
+
+	setup(Auth(auth))
+	defer teardown()
+
+	want := &GenresListResp{}
+	want.InvocationInfo.ReqID = "Genres.List"
+
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		// Only the refreshed token is accepted
+		if r.Header.Get("Authorization") != "OAuth token2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"name":"session-expired"}}`)
+			return
+		}
+		b, err := json.Marshal(want)
+		assert.NoError(t, err)
+		fmt.Fprint(w, string(b))
+	})
+
+	result, _, err := client.Genres().List(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+}
+
+func TestAuthDoer_RenewsTokenOnceConcurrently(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	defer oauth.Close()
+
+	auth := oauth.authenticator(nil)
+	_, err := auth.PasswordLogin(context.Background(), "user", "secret")
+	assert.NoError(t, err)
+
+	setup(Auth(auth))
+	defer teardown()
+
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "OAuth token2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"name":"session-expired"}}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := client.Genres().List(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// Refresh token is spent once, the other requests get the new token
+	assert.Equal(t, 2, oauth.issued)
+}
+
+func TestAuthDoer_Relogin(t *testing.T) {
+	relogins := 0
+	auth := NewAuthenticator(&AuthOptions{
+		Relogin: func(ctx context.Context, a *Authenticator) (*Token, error) {
+			relogins++
+			return &Token{AccessToken: "relogged"}, nil
+		},
+	})
+
+	setup(Auth(auth))
+	defer teardown()
+
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "OAuth relogged", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{}`)
+	})
+
+	_, _, err := client.Genres().List(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, relogins)
+}
+
+func TestAuthDoer_Unauthorized(t *testing.T) {
+	auth := NewAuthenticator(nil)
+	assert.NoError(t, auth.SetToken(context.Background(), &Token{AccessToken: "revoked"}))
+
+	setup(Auth(auth))
+	defer teardown()
+
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	_, _, err := client.Genres().List(context.Background())
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestClient_Authorize(t *testing.T) {
+	setup(func(c *Client) { c.SetUserID(0) })
+	defer teardown()
+
+	want := &AccountStatusResp{}
+	want.Result.Account.UID = 42
+
+	mux.HandleFunc("/account/status", func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Marshal(want)
+		assert.NoError(t, err)
+		fmt.Fprint(w, string(b))
+	})
+
+	assert.NoError(t, client.Authorize(context.Background()))
+	assert.Equal(t, 42, client.UserID())
+}
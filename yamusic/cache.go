@@ -0,0 +1,561 @@
+package yamusic
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEndpoint is a group of cacheable endpoints sharing one TTL
+type CacheEndpoint string
+
+const (
+	CacheGenres    CacheEndpoint = "genres"
+	CacheTracks    CacheEndpoint = "tracks"
+	CacheAlbums    CacheEndpoint = "albums"
+	CacheArtists   CacheEndpoint = "artists"
+	CachePlaylists CacheEndpoint = "playlists"
+)
+
+type (
+	// CacheStore stores cached responses by key. Implementations must be
+	// safe for concurrent use.
+	CacheStore interface {
+		// Get returns value by key if it exists and isn't expired
+		Get(key string) ([]byte, bool)
+		// Set stores value by key for ttl
+		Set(key string, value []byte, ttl time.Duration)
+		// Delete removes value by key
+		Delete(key string)
+	}
+	// CacheOptions configures caching of responses
+	CacheOptions struct {
+		// Store is where responses are kept. If nil, in-memory LRU store
+		// is used or on-disk one if OnDisk is set.
+		Store CacheStore
+		// OnDisk stores responses in .cache directory under configured
+		// output directory. It's ignored if Store is set.
+		OnDisk bool
+		// TTL overrides default time to live of endpoints.
+		// Zero duration disables caching of endpoint.
+		TTL map[CacheEndpoint]time.Duration
+	}
+	// CacheDoer is a Doer that serves responses of immutable metadata
+	// from CacheStore
+	CacheDoer struct {
+		doer  Doer
+		store CacheStore
+		ttl   map[CacheEndpoint]time.Duration
+
+		mu sync.Mutex
+		// revisions are the latest known revisions of playlists by uid/kind
+		revisions map[string]int
+		// owners are uids of playlist owners by their form in URL,
+		// e.g. login
+		owners map[string]int
+	}
+	// cacheEntry is a cached response
+	cacheEntry struct {
+		Header http.Header `json:"header"`
+		Body   []byte      `json:"body"`
+		// Playlist and Revision are set for responses of playlists
+		Playlist string `json:"playlist,omitempty"`
+		Revision int    `json:"revision,omitempty"`
+	}
+	// bypassCacheKey is a context key to skip reading from cache
+	bypassCacheKey struct{}
+)
+
+var defaultCacheTTL = map[CacheEndpoint]time.Duration{
+	CacheGenres:    24 * time.Hour,
+	CacheTracks:    24 * time.Hour,
+	CacheAlbums:    24 * time.Hour,
+	CacheArtists:   6 * time.Hour,
+	CachePlaylists: time.Hour,
+}
+
+var (
+	reCacheGenres   = regexp.MustCompile(`/genres$`)
+	reCacheTrack    = regexp.MustCompile(`/tracks/\d+(:\d+)?$`)
+	reCacheTracks   = regexp.MustCompile(`/tracks$`)
+	reCacheAlbum    = regexp.MustCompile(`/albums/\d+(/with-tracks)?$`)
+	reCacheAlbums   = regexp.MustCompile(`/albums$`)
+	reCacheArtists  = regexp.MustCompile(`/artists/\d+/[a-z-]+$`)
+	rePlaylist      = regexp.MustCompile(`/users/([^/]+)/playlists/(\d+)((?:/[a-z-]+)*)$`)
+	rePlaylistsList = regexp.MustCompile(`/users/[^/]+/playlists(/list)?$`)
+	rePlaylistOwner = regexp.MustCompile(`/users/([^/]+)/playlists`)
+)
+
+// Cache sets caching of responses for Yandex.Music client.
+// If opts is nil, responses are cached in memory with default TTLs.
+func Cache(opts *CacheOptions) func(*Client) {
+	return func(c *Client) {
+		if opts == nil {
+			opts = &CacheOptions{}
+		}
+		c.cache = opts
+	}
+}
+
+// BypassCache returns context making requests skip reading from cache.
+// Fresh responses are still stored in cache. It's used to read revision
+// of playlist right before changing it, since cached revision could be
+// changed meanwhile from another device.
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// NewCacheDoer returns a Doer that caches responses of requests made by doer
+func NewCacheDoer(doer Doer, store CacheStore, ttl map[CacheEndpoint]time.Duration) *CacheDoer {
+	d := &CacheDoer{
+		doer:      doer,
+		store:     store,
+		ttl:       make(map[CacheEndpoint]time.Duration, len(defaultCacheTTL)),
+		revisions: make(map[string]int),
+		owners:    make(map[string]int),
+	}
+	for endpoint, t := range defaultCacheTTL {
+		d.ttl[endpoint] = t
+	}
+	for endpoint, t := range ttl {
+		d.ttl[endpoint] = t
+	}
+	return d
+}
+
+// Do returns cached response if there is one or sends request and caches
+// its response
+func (d *CacheDoer) Do(req *http.Request) (*http.Response, error) {
+	endpoint, cacheable := cacheEndpointOf(req)
+	ttl := d.ttl[endpoint]
+	if !cacheable || ttl <= 0 {
+		resp, err := d.doer.Do(req)
+		if err == nil {
+			d.observe(req, resp)
+		}
+		return resp, err
+	}
+
+	key, err := cacheKey(req)
+	if err != nil {
+		return d.doer.Do(req)
+	}
+
+	bypass, _ := req.Context().Value(bypassCacheKey{}).(bool)
+	if !bypass {
+		if entry, ok := d.get(key); ok {
+			return &http.Response{
+				Status:        "200 OK",
+				StatusCode:    http.StatusOK,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        entry.Header,
+				Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+				ContentLength: int64(len(entry.Body)),
+				Request:       req,
+			}, nil
+		}
+	}
+
+	resp, err := d.doer.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := cacheEntry{Header: resp.Header.Clone(), Body: body}
+	if endpoint == CachePlaylists {
+		if playlists := playlistsOf(body); len(playlists) == 1 {
+			entry.Playlist = playlistKey(playlists[0].UID, playlists[0].Kind)
+			entry.Revision = playlists[0].Revision
+			d.observeRevisions(playlists...)
+			d.observeOwner(req.URL.Path, playlists)
+		}
+	}
+
+	// Response could have API error inside, so it's cached
+	// only if it can't be mistaken for one
+	if checkResponse(resp, body) == nil {
+		if b, err := json.Marshal(entry); err == nil {
+			d.store.Set(key, b, ttl)
+		}
+	}
+
+	return resp, nil
+}
+
+// get returns cached entry if it exists and is up to date
+func (d *CacheDoer) get(key string) (*cacheEntry, bool) {
+	b, ok := d.store.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	entry := new(cacheEntry)
+	if err := json.Unmarshal(b, entry); err != nil {
+		d.store.Delete(key)
+		return nil, false
+	}
+
+	if entry.Playlist != "" {
+		d.mu.Lock()
+		revision, known := d.revisions[entry.Playlist]
+		d.mu.Unlock()
+		if known && revision != entry.Revision {
+			d.store.Delete(key)
+			return nil, false
+		}
+	}
+
+	return entry, true
+}
+
+// observe records revisions of playlists returned by requests that are not
+// cached, e.g. listing or changing of playlists, so stale cached playlists
+// are not served anymore
+func (d *CacheDoer) observe(req *http.Request, resp *http.Response) {
+	path := req.URL.Path
+	if resp.StatusCode != http.StatusOK ||
+		!rePlaylist.MatchString(path) && !rePlaylistsList.MatchString(path) {
+		return
+	}
+
+	// Change of playlist, e.g. of its cover, doesn't always change its
+	// revision, so cached playlist is forgotten on any change
+	if m := rePlaylist.FindStringSubmatch(path); m != nil && m[3] != "" && req.Method == http.MethodPost {
+		kind, _ := strconv.Atoi(m[2])
+		d.forget(m[1], kind)
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	playlists := playlistsOf(body)
+	d.observeRevisions(playlists...)
+	d.observeOwner(path, playlists)
+}
+
+// observeOwner records uid of owner of playlists requested by path, so
+// playlists requested by owner login are known by the same uid/kind
+func (d *CacheDoer) observeOwner(path string, playlists []playlistRevision) {
+	m := rePlaylistOwner.FindStringSubmatch(path)
+	if m == nil || len(playlists) == 0 {
+		return
+	}
+	for _, p := range playlists[1:] {
+		if p.UID != playlists[0].UID {
+			return
+		}
+	}
+
+	d.mu.Lock()
+	d.owners[m[1]] = playlists[0].UID
+	d.mu.Unlock()
+}
+
+// forget makes cached playlist stale after its change or deletion. Owner
+// is as in URL, so if its uid isn't known, playlists of every owner with
+// the kind are forgotten.
+func (d *CacheDoer) forget(owner string, kind int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	uid, known := d.owners[owner]
+	if !known {
+		if id, err := strconv.Atoi(owner); err == nil {
+			uid, known = id, true
+		}
+	}
+	if known {
+		d.revisions[playlistKey(uid, kind)] = -1
+		return
+	}
+
+	for key := range d.revisions {
+		if strings.HasSuffix(key, "/"+strconv.Itoa(kind)) {
+			d.revisions[key] = -1
+		}
+	}
+}
+
+// observeRevisions records the latest known revisions of playlists
+func (d *CacheDoer) observeRevisions(playlists ...playlistRevision) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, p := range playlists {
+		d.revisions[playlistKey(p.UID, p.Kind)] = p.Revision
+	}
+}
+
+// playlistRevision is a part of playlist identifying its version
+type playlistRevision struct {
+	UID      int `json:"uid"`
+	Kind     int `json:"kind"`
+	Revision int `json:"revision"`
+}
+
+// playlistsOf returns revisions of playlists in response body. Result
+// of response is either one playlist or a list of them.
+func playlistsOf(body []byte) []playlistRevision {
+	var one struct {
+		Result playlistRevision `json:"result"`
+	}
+	if err := json.Unmarshal(body, &one); err == nil {
+		if one.Result.UID == 0 {
+			return nil
+		}
+		return []playlistRevision{one.Result}
+	}
+
+	var many struct {
+		Result []playlistRevision `json:"result"`
+	}
+	if err := json.Unmarshal(body, &many); err != nil {
+		return nil
+	}
+	return many.Result
+}
+
+func playlistKey(uid, kind int) string {
+	return strconv.Itoa(uid) + "/" + strconv.Itoa(kind)
+}
+
+// cacheEndpointOf returns endpoint of request if its response can be cached
+func cacheEndpointOf(req *http.Request) (CacheEndpoint, bool) {
+	path := req.URL.Path
+	switch req.Method {
+	case http.MethodGet:
+		switch {
+		case reCacheGenres.MatchString(path):
+			return CacheGenres, true
+		case reCacheTrack.MatchString(path):
+			return CacheTracks, true
+		case reCacheAlbum.MatchString(path):
+			return CacheAlbums, true
+		case reCacheArtists.MatchString(path):
+			return CacheArtists, true
+		case rePlaylist.MatchString(path):
+			if m := rePlaylist.FindStringSubmatch(path); m[3] == "" {
+				return CachePlaylists, true
+			}
+		}
+	case http.MethodPost:
+		// Batch get of tracks and albums are POST requests
+		// with ids in the form
+		switch {
+		case reCacheTracks.MatchString(path):
+			return CacheTracks, true
+		case reCacheAlbums.MatchString(path):
+			return CacheAlbums, true
+		}
+	}
+	return "", false
+}
+
+// cacheKey returns key of request which depends on its method, URL, body
+// and authorization, so different accounts don't share cached responses
+func cacheKey(req *http.Request) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, req.Method+" "+req.URL.String()+"\n")
+	io.WriteString(h, req.Header.Get("Authorization")+"\n")
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return "", io.ErrUnexpectedEOF
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type (
+	// MemoryCache is an in-memory LRU CacheStore
+	MemoryCache struct {
+		mu       sync.Mutex
+		capacity int
+		items    map[string]*list.Element
+		order    *list.List
+	}
+	memoryCacheItem struct {
+		key     string
+		value   []byte
+		expires time.Time
+	}
+)
+
+// NewMemoryCache returns in-memory LRU store keeping at most capacity
+// values. If capacity isn't positive, 1000 is used.
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns value by key if it exists and isn't expired
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := el.Value.(*memoryCacheItem)
+	if time.Now().After(item.expires) {
+		m.order.Remove(el)
+		delete(m.items, key)
+		return nil, false
+	}
+
+	m.order.MoveToFront(el)
+	return item.value, true
+}
+
+// Set stores value by key for ttl evicting the least recently used value
+// if store is full
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		item := el.Value.(*memoryCacheItem)
+		item.value = value
+		item.expires = time.Now().Add(ttl)
+		m.order.MoveToFront(el)
+		return
+	}
+
+	m.items[key] = m.order.PushFront(&memoryCacheItem{
+		key:     key,
+		value:   value,
+		expires: time.Now().Add(ttl),
+	})
+
+	for m.order.Len() > m.capacity {
+		el := m.order.Back()
+		m.order.Remove(el)
+		delete(m.items, el.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete removes value by key
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.order.Remove(el)
+		delete(m.items, key)
+	}
+}
+
+type (
+	// DiskCache is a CacheStore keeping every value in its own file
+	DiskCache struct {
+		dir string
+	}
+	diskCacheItem struct {
+		Expires time.Time `json:"expires"`
+		Value   []byte    `json:"value"`
+	}
+)
+
+// NewDiskCache returns on-disk store in dir. The directory is created
+// if it doesn't exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get returns value by key if it exists and isn't expired
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	b, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var item diskCacheItem
+	if err := json.Unmarshal(b, &item); err != nil || time.Now().After(item.Expires) {
+		d.Delete(key)
+		return nil, false
+	}
+
+	return item.Value, true
+}
+
+// Set stores value by key for ttl. Cache is best-effort,
+// so write errors are ignored.
+func (d *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	b, err := json.Marshal(diskCacheItem{Expires: time.Now().Add(ttl), Value: value})
+	if err != nil {
+		return
+	}
+
+	// Write to temporary file first, so concurrent readers never see
+	// partially written value
+	tmp, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete removes value by key
+func (d *DiskCache) Delete(key string) {
+	os.Remove(d.path(key))
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
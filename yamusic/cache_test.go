@@ -0,0 +1,338 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheDoer_Genres(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	want := &GenresListResp{}
+	want.InvocationInfo.ReqID = "Genres.List"
+
+	requests := 0
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		b, err := json.Marshal(want)
+		assert.NoError(t, err)
+		fmt.Fprint(w, string(b))
+	})
+
+	for i := 0; i < 3; i++ {
+		result, _, err := client.Genres().List(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+	}
+	assert.Equal(t, 1, requests)
+
+	_, _, err := client.Genres().List(BypassCache(context.Background()))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+func TestCacheDoer_TracksByForm(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	requests := map[string]int{}
+	mux.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		requests[r.FormValue("track-ids")]++
+		fmt.Fprint(w, `{"result":[]}`)
+	})
+
+	for _, ids := range [][]string{{"1", "2"}, {"3"}, {"1", "2"}} {
+		_, err := client.Tracks().GetAll(context.Background(), ids, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{"1,2": 1, "3": 1}, requests)
+}
+
+func TestCacheDoer_ErrorsAreNotCached(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for i := 0; i < 2; i++ {
+		_, _, err := client.Genres().List(context.Background())
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, requests)
+}
+
+func TestCacheDoer_PlaylistRevision(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	kind := 1004
+	revision := 1
+
+	requests := 0
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":%v}}`, userID, kind, revision)
+		},
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/change-relative", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			revision++
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":%v}}`, userID, kind, revision)
+		},
+	)
+
+	get := func() int {
+		result, _, err := client.Playlists().Get(context.Background(), 0, kind)
+		assert.NoError(t, err)
+		return result.Result.Revision
+	}
+
+	assert.Equal(t, 1, get())
+	assert.Equal(t, 1, get())
+	assert.Equal(t, 1, requests)
+
+	_, _, err := client.Playlists().AddTracks(
+		context.Background(),
+		kind,
+		revision,
+		[]PlaylistsTrack{{ID: 1, AlbumID: 1}},
+		nil,
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, get())
+	assert.Equal(t, 2, get())
+	assert.Equal(t, 2, requests)
+}
+
+func TestCacheDoer_PlaylistRevisionFromList(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	kind := 1004
+	revision := 1
+
+	requests := 0
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":%v}}`, userID, kind, revision)
+		},
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/list", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"result":[{"uid":%v,"kind":%v,"revision":%v}]}`, userID, kind, revision)
+		},
+	)
+
+	_, _, err := client.Playlists().Get(context.Background(), 0, kind)
+	assert.NoError(t, err)
+
+	// Playlist was changed by someone else
+	revision = 5
+	_, _, err = client.Playlists().List(context.Background(), 0)
+	assert.NoError(t, err)
+
+	result, _, err := client.Playlists().Get(context.Background(), 0, kind)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result.Result.Revision)
+	assert.Equal(t, 2, requests)
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(2)
+
+	cache.Set("a", []byte("1"), time.Hour)
+	cache.Set("b", []byte("2"), time.Hour)
+	_, _ = cache.Get("a")
+	cache.Set("c", []byte("3"), time.Hour)
+
+	// b is the least recently used one
+	_, ok := cache.Get("b")
+	assert.False(t, ok)
+
+	v, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+
+	cache.Set("d", []byte("4"), -time.Second)
+	_, ok = cache.Get("d")
+	assert.False(t, ok)
+
+	cache.Delete("a")
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+}
+
+func TestDiskCache(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	assert.NoError(t, err)
+
+	cache.Set("a", []byte("1"), time.Hour)
+	v, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+
+	cache.Set("b", []byte("2"), -time.Second)
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	cache.Delete("a")
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCacheDoer_PlaylistDeletedByLogin(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	kind := 1004
+
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":1}}`, userID, kind)
+	}
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v", userID, kind), handler)
+	mux.HandleFunc(fmt.Sprintf("/users/login/playlists/%v", kind), handler)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/login/playlists/%v/delete", kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"result":"ok"}`)
+		},
+	)
+
+	get := func(owner any) {
+		req, err := client.NewRequest(http.MethodGet, fmt.Sprintf("users/%v/playlists/%v", owner, kind), nil)
+		assert.NoError(t, err)
+		_, err = client.Do(context.Background(), req, new(PlaylistsGetResp))
+		assert.NoError(t, err)
+	}
+
+	get(userID)
+	get(userID)
+	assert.Equal(t, 1, requests)
+
+	get("login")
+	assert.Equal(t, 2, requests)
+
+	req, err := client.NewRequest(http.MethodPost, fmt.Sprintf("users/login/playlists/%v/delete", kind), nil)
+	assert.NoError(t, err)
+	_, err = client.Do(context.Background(), req, new(PlaylistsDeleteResp))
+	assert.NoError(t, err)
+
+	// Playlist cached by uid is forgotten as well
+	get(userID)
+	assert.Equal(t, 3, requests)
+}
+
+func TestCacheDoer_ChangeReadsFreshRevision(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	kind := 1004
+	revision := 1
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":%v,"tracks":[
+				{"id":1,"track":{"id":"1","artists":[{"id":10}],"albums":[{"id":100}]}}
+			]}}`, userID, kind, revision)
+		},
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/recommendations", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"result":{"tracks":[{"id":"2","artists":[{"id":10}],"albums":[{"id":200}]}]}}`)
+		},
+	)
+	mux.HandleFunc("/tracks/1/similar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"similarTracks":[]}}`)
+	})
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/change-relative", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, r.ParseForm())
+			if r.FormValue("revision") != strconv.Itoa(revision) {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				fmt.Fprint(w, `{"error":{"name":"wrong-revision"}}`)
+				return
+			}
+			revision++
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":%v}}`, userID, kind, revision)
+		},
+	)
+
+	_, _, err := client.Playlists().Get(context.Background(), 0, kind)
+	assert.NoError(t, err)
+
+	// Playlist was changed from another device
+	revision = 5
+
+	added, err := client.Playlists().Extend(context.Background(), kind, 1)
+	assert.NoError(t, err)
+	assert.Len(t, added, 1)
+	assert.Equal(t, 6, revision)
+}
+
+func TestCacheDoer_PlaylistCoverUploaded(t *testing.T) {
+	setup(Cache(nil))
+	defer teardown()
+
+	kind := 1004
+	custom := false
+
+	requests := 0
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":1,"cover":{"custom":%v}}}`, userID, kind, custom)
+		},
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/cover/upload", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			custom = true
+			// Revision isn't changed by cover
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":%v,"revision":1,"cover":{"custom":true}}}`, userID, kind)
+		},
+	)
+
+	get := func() bool {
+		result, _, err := client.Playlists().Get(context.Background(), 0, kind)
+		assert.NoError(t, err)
+		return result.Result.Cover.Custom
+	}
+
+	assert.False(t, get())
+	assert.False(t, get())
+	assert.Equal(t, 1, requests)
+
+	_, _, err := client.Playlists().UploadCover(context.Background(), kind, strings.NewReader("jpeg"))
+	assert.NoError(t, err)
+
+	assert.True(t, get())
+	assert.True(t, get())
+	assert.Equal(t, 2, requests)
+}
@@ -0,0 +1,111 @@
+package yamusic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ClientError is a sentinel error kind that can be matched with errors.Is
+// against errors returned by Client.Do and every service method.
+type ClientError string
+
+func (ce ClientError) Error() string { return string(ce) }
+
+var (
+	ErrUnauthorized     = ClientError("unauthorized")
+	ErrNotFound         = ClientError("not found")
+	ErrRateLimited      = ClientError("rate limited")
+	ErrRevisionConflict = ClientError("playlist revision conflict")
+)
+
+type (
+	// APIError is returned when Yandex.Music API answers with a non-2xx
+	// status code or with a non-empty error object in the response body.
+	APIError struct {
+		// Response is the HTTP response that caused this error
+		Response *http.Response
+		// StatusCode is the HTTP status code of the response
+		StatusCode int
+		// Name is the value of Error.Name from the response body
+		Name string
+		// Message is the value of Error.Message from the response body
+		Message string
+		// ReqID is the value of InvocationInfo.ReqID from the response body
+		ReqID string
+	}
+	// errorResp is the part of every API response describing an error
+	errorResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+	}
+)
+
+func (e *APIError) Error() string {
+	msg := e.Name
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.ReqID != "" {
+		return fmt.Sprintf("yamusic: %d %s (req-id %s)", e.StatusCode, msg, e.ReqID)
+	}
+	return fmt.Sprintf("yamusic: %d %s", e.StatusCode, msg)
+}
+
+// Unwrap returns the sentinel error matching the status code and the error
+// name of the response, so callers can use errors.Is. It returns nil if the
+// error doesn't fall into any known kind.
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized ||
+		e.Name == "session-expired" ||
+		e.Name == "unauthorized":
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound || e.Name == "not-found":
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusPreconditionFailed ||
+		e.StatusCode == http.StatusConflict ||
+		e.Name == "wrong-revision":
+		return ErrRevisionConflict
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes error object. Some endpoints answer with a bare
+// string instead of {"name": ..., "message": ...}, so both forms are accepted.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		e.Name = name
+		return nil
+	}
+
+	type plain Error
+	return json.Unmarshal(data, (*plain)(e))
+}
+
+// checkResponse returns *APIError if resp has a non-2xx status code or its
+// body contains an error object. Otherwise it returns nil.
+func checkResponse(resp *http.Response, body []byte) error {
+	var errResp errorResp
+	// Body could be empty, XML or not JSON at all, so decoding errors
+	// are ignored and only the status code is taken into account.
+	_ = json.Unmarshal(body, &errResp)
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 && errResp.Error.Name == "" {
+		return nil
+	}
+
+	return &APIError{
+		Response:   resp,
+		StatusCode: resp.StatusCode,
+		Name:       errResp.Error.Name,
+		Message:    errResp.Error.Message,
+		ReqID:      errResp.InvocationInfo.ReqID,
+	}
+}
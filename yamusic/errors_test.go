@@ -0,0 +1,88 @@
+package yamusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Do_APIError(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{
+			status: http.StatusUnauthorized,
+			body:   `{"invocationInfo":{"req-id":"Errors.Unauthorized"},"error":{"name":"session-expired","message":"Your OAuth token is expired"}}`,
+			want:   ErrUnauthorized,
+		},
+		{
+			status: http.StatusNotFound,
+			body:   `{"invocationInfo":{"req-id":"Errors.NotFound"},"error":{"name":"not-found","message":"Playlist not found"}}`,
+			want:   ErrNotFound,
+		},
+		{
+			status: http.StatusTooManyRequests,
+			body:   ``,
+			want:   ErrRateLimited,
+		},
+		{
+			status: http.StatusPreconditionFailed,
+			body:   `{"invocationInfo":{"req-id":"Errors.WrongRevision"},"error":{"name":"wrong-revision","message":"Wrong revision"}}`,
+			want:   ErrRevisionConflict,
+		},
+		{
+			status: http.StatusOK,
+			body:   `{"invocationInfo":{"req-id":"Errors.InBody"},"error":"not-found"}`,
+			want:   ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprint(tc.status), func(t *testing.T) {
+			setup()
+			defer teardown()
+
+			mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			})
+
+			_, resp, err := client.Genres().List(context.Background())
+
+			assert.ErrorIs(t, err, tc.want)
+
+			var apiErr *APIError
+			if assert.True(t, errors.As(err, &apiErr)) {
+				assert.Equal(t, tc.status, apiErr.StatusCode)
+				assert.Equal(t, resp, apiErr.Response)
+			}
+		})
+	}
+}
+
+func TestClient_Do_APIErrorFields(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/account/status", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"invocationInfo":{"req-id":"Account.GetStatus"},"error":{"name":"validate","message":"Parameters requirements are not met"}}`)
+	})
+
+	_, _, err := client.Account().GetStatus(context.Background())
+
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, "validate", apiErr.Name)
+		assert.Equal(t, "Parameters requirements are not met", apiErr.Message)
+		assert.Equal(t, "Account.GetStatus", apiErr.ReqID)
+	}
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
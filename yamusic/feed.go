@@ -2,12 +2,34 @@ package yamusic
 
 import (
 	"context"
+	"encoding/json"
+	"iter"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+// Types of feed events
+const (
+	FeedEventTracks       = "tracks"
+	FeedEventArtists      = "artists"
+	FeedEventAlbums       = "albums"
+	FeedEventGenreTop     = "genre-top"
+	FeedEventPromotion    = "promotion"
+	FeedEventNotification = "notification"
+)
+
+// Types of playlists generated for the user
+const (
+	GeneratedPlaylistOfTheDay = "playlistOfTheDay"
+	GeneratedDejaVu           = "neverHeard"
+	GeneratedPremiere         = "recentTracks"
+	GeneratedMissedLikes      = "missedLikes"
+	GeneratedOrigin           = "origin"
+)
+
 type (
-	// FeedService is a service to deal with accounts.
+	// FeedService is a service to deal with feed of events.
 	FeedService struct {
 		client *Client
 	}
@@ -17,358 +39,255 @@ type (
 		InvocationInfo InvocationInfo `json:"invocationInfo"`
 		Error          Error          `json:"error"`
 		Result         struct {
-			CanGetMoreEvents   bool          `json:"canGetMoreEvents"`
-			Pumpkin            bool          `json:"pumpkin"`
-			Today              string        `json:"today"`
-			GeneratedPlaylists []interface{} `json:"generatedPlaylists"`
-			Headlines          []struct {
-				Type    string `json:"type"`
-				ID      string `json:"id"`
-				Message string `json:"message"`
-			} `json:"headlines"`
-			Days []struct {
-				Day    string `json:"day"`
-				Events []struct {
-					ID          string `json:"id"`
-					Type        string `json:"type"`
-					TypeForFrom string `json:"typeForFrom,omitempty"`
-					Title       []struct {
-						Type string `json:"type"`
-						Text string `json:"text"`
-					} `json:"title,omitempty"`
-					Promo struct {
-						PromoID       string        `json:"promoId"`
-						Category      string        `json:"category"`
-						TitleURL      string        `json:"titleUrl"`
-						SubtitleURL   string        `json:"subtitleUrl"`
-						Title         string        `json:"title"`
-						Subtitle      string        `json:"subtitle"`
-						Heading       string        `json:"heading"`
-						Description   string        `json:"description"`
-						Background    string        `json:"background"`
-						ImagePosition string        `json:"imagePosition"`
-						PromotionType string        `json:"promotionType"`
-						Tags          []interface{} `json:"tags"`
-						StartDate     time.Time     `json:"startDate"`
-						Pager         struct {
-							Total   int `json:"total"`
-							Page    int `json:"page"`
-							PerPage int `json:"perPage"`
-						} `json:"pager"`
-						Playlists []struct {
-							Playlist struct {
-								UID                  int       `json:"uid"`
-								Kind                 int       `json:"kind"`
-								Revision             int       `json:"revision"`
-								TrackCount           int       `json:"trackCount"`
-								DurationMs           int       `json:"durationMs"`
-								Collective           bool      `json:"collective"`
-								IsBanner             bool      `json:"isBanner"`
-								IsPremiere           bool      `json:"isPremiere"`
-								Available            bool      `json:"available"`
-								Title                string    `json:"title"`
-								Description          string    `json:"description"`
-								DescriptionFormatted string    `json:"descriptionFormatted"`
-								Visibility           string    `json:"visibility"`
-								BackgroundColor      string    `json:"backgroundColor"`
-								TextColor            string    `json:"textColor"`
-								Image                string    `json:"image"`
-								OgImage              string    `json:"ogImage"`
-								Created              time.Time `json:"created"`
-								Modified             time.Time `json:"modified"`
-								Owner                struct {
-									UID      int    `json:"uid"`
-									Login    string `json:"login"`
-									Name     string `json:"name"`
-									Verified bool   `json:"verified"`
-								} `json:"owner"`
-								Cover struct {
-									Type    string `json:"type"`
-									Dir     string `json:"dir"`
-									Version string `json:"version"`
-									URI     string `json:"uri"`
-									Custom  bool   `json:"custom"`
-								} `json:"cover"`
-								Tags []struct {
-									ID    string `json:"id"`
-									Value string `json:"value"`
-								} `json:"tags"`
-							} `json:"playlist"`
-							SomeArtists []struct {
-								Various          bool     `json:"various"`
-								Composer         bool     `json:"composer"`
-								Available        bool     `json:"available"`
-								TicketsAvailable bool     `json:"ticketsAvailable"`
-								ID               string   `json:"id"`
-								Name             string   `json:"name"`
-								OgImage          string   `json:"ogImage"`
-								Genres           []string `json:"genres"`
-								Cover            struct {
-									Type   string `json:"type"`
-									Prefix string `json:"prefix"`
-									URI    string `json:"uri"`
-								} `json:"cover,omitempty"`
-								Counts struct {
-									Tracks       int `json:"tracks"`
-									DirectAlbums int `json:"directAlbums"`
-									AlsoAlbums   int `json:"alsoAlbums"`
-									AlsoTracks   int `json:"alsoTracks"`
-								} `json:"counts"`
-								Ratings struct {
-									Day   int `json:"day"`
-									Week  int `json:"week"`
-									Month int `json:"month"`
-								} `json:"ratings,omitempty"`
-								Links []struct {
-									Title         string `json:"title"`
-									Href          string `json:"href"`
-									Type          string `json:"type"`
-									SocialNetwork string `json:"socialNetwork,omitempty"`
-								} `json:"links"`
-							} `json:"someArtists"`
-							ArtistsCount int `json:"artistsCount"`
-						} `json:"playlists"`
-					} `json:"promo,omitempty"`
-					Message          string `json:"message,omitempty"`
-					Genre            string `json:"genre,omitempty"`
-					RadioIsAvailable bool   `json:"radioIsAvailable,omitempty"`
-					Tracks           []struct {
-						DurationMs               int    `json:"durationMs"`
-						FileSize                 int    `json:"fileSize"`
-						Available                bool   `json:"available"`
-						AvailableForPremiumUsers bool   `json:"availableForPremiumUsers"`
-						LyricsAvailable          bool   `json:"lyricsAvailable"`
-						ID                       string `json:"id"`
-						RealID                   string `json:"realId"`
-						Title                    string `json:"title"`
-						StorageDir               string `json:"storageDir"`
-						CoverURI                 string `json:"coverUri"`
-						OgImage                  string `json:"ogImage"`
-						Major                    struct {
-							ID   int    `json:"id"`
-							Name string `json:"name"`
-						} `json:"major"`
-						Normalization struct {
-							Gain float64 `json:"gain"`
-							Peak int     `json:"peak"`
-						} `json:"normalization"`
-						Artists []struct {
-							ID       int           `json:"id"`
-							Name     string        `json:"name"`
-							Various  bool          `json:"various"`
-							Composer bool          `json:"composer"`
-							Genres   []interface{} `json:"genres"`
-							Cover    struct {
-								Type   string `json:"type"`
-								Prefix string `json:"prefix"`
-								URI    string `json:"uri"`
-							} `json:"cover"`
-						} `json:"artists"`
-						Albums []struct {
-							ID                       int           `json:"id"`
-							Year                     int           `json:"year"`
-							TrackCount               int           `json:"trackCount"`
-							Recent                   bool          `json:"recent"`
-							VeryImportant            bool          `json:"veryImportant"`
-							Available                bool          `json:"available"`
-							AvailableForPremiumUsers bool          `json:"availableForPremiumUsers"`
-							AvailableForMobile       bool          `json:"availableForMobile"`
-							AvailablePartially       bool          `json:"availablePartially"`
-							Title                    string        `json:"title"`
-							CoverURI                 string        `json:"coverUri"`
-							OgImage                  string        `json:"ogImage"`
-							Genre                    string        `json:"genre"`
-							Buy                      []interface{} `json:"buy"`
-							Bests                    []interface{} `json:"bests"`
-							Artists                  []struct {
-								ID       int    `json:"id"`
-								Name     string `json:"name"`
-								Various  bool   `json:"various"`
-								Composer bool   `json:"composer"`
-								Cover    struct {
-									Type   string `json:"type"`
-									Prefix string `json:"prefix"`
-									URI    string `json:"uri"`
-								} `json:"cover"`
-								Genres []interface{} `json:"genres"`
-							} `json:"artists"`
-							Labels []struct {
-								ID          int    `json:"id"`
-								Name        string `json:"name"`
-								Description string `json:"description"`
-								Image       string `json:"image"`
-							} `json:"labels"`
-							TrackPosition struct {
-								Volume int `json:"volume"`
-								Index  int `json:"index"`
-							} `json:"trackPosition"`
-						} `json:"albums"`
-					} `json:"tracks,omitempty"`
-				} `json:"events"`
-				TracksToPlay []struct {
-					DurationMs               int    `json:"durationMs"`
-					FileSize                 int    `json:"fileSize"`
-					Available                bool   `json:"available"`
-					AvailableForPremiumUsers bool   `json:"availableForPremiumUsers"`
-					Best                     bool   `json:"best,omitempty"`
-					LyricsAvailable          bool   `json:"lyricsAvailable"`
-					ID                       string `json:"id"`
-					RealID                   string `json:"realId"`
-					Title                    string `json:"title"`
-					StorageDir               string `json:"storageDir"`
-					CoverURI                 string `json:"coverUri"`
-					OgImage                  string `json:"ogImage"`
-					Version                  string `json:"version,omitempty"`
-					ContentWarning           string `json:"contentWarning,omitempty"`
-					Major                    struct {
-						ID   int    `json:"id"`
-						Name string `json:"name"`
-					} `json:"major"`
-					Normalization struct {
-						Gain float64 `json:"gain"`
-						Peak int     `json:"peak"`
-					} `json:"normalization"`
-					Artists []struct {
-						ID       int    `json:"id"`
-						Name     string `json:"name"`
-						Various  bool   `json:"various"`
-						Composer bool   `json:"composer"`
-						Cover    struct {
-							Type   string `json:"type"`
-							Prefix string `json:"prefix"`
-							URI    string `json:"uri"`
-						} `json:"cover"`
-						Genres []interface{} `json:"genres"`
-					} `json:"artists"`
-					Albums []struct {
-						ID                       int           `json:"id"`
-						Year                     int           `json:"year"`
-						TrackCount               int           `json:"trackCount"`
-						Recent                   bool          `json:"recent"`
-						VeryImportant            bool          `json:"veryImportant"`
-						Available                bool          `json:"available"`
-						AvailableForPremiumUsers bool          `json:"availableForPremiumUsers"`
-						AvailableForMobile       bool          `json:"availableForMobile"`
-						AvailablePartially       bool          `json:"availablePartially"`
-						Title                    string        `json:"title"`
-						Type                     string        `json:"type"`
-						CoverURI                 string        `json:"coverUri"`
-						OgImage                  string        `json:"ogImage"`
-						Genre                    string        `json:"genre"`
-						Bests                    []interface{} `json:"bests"`
-						Buy                      []interface{} `json:"buy"`
-						ReleaseDate              time.Time     `json:"releaseDate"`
-						Artists                  []struct {
-							ID       int    `json:"id"`
-							Name     string `json:"name"`
-							Various  bool   `json:"various"`
-							Composer bool   `json:"composer"`
-							Cover    struct {
-								Type   string `json:"type"`
-								Prefix string `json:"prefix"`
-								URI    string `json:"uri"`
-							} `json:"cover"`
-							Genres []interface{} `json:"genres"`
-						} `json:"artists"`
-						Labels []struct {
-							ID          int    `json:"id"`
-							Name        string `json:"name"`
-							Description string `json:"description"`
-							Image       string `json:"image"`
-						} `json:"labels"`
-						TrackPosition struct {
-							Volume int `json:"volume"`
-							Index  int `json:"index"`
-						} `json:"trackPosition"`
-					} `json:"albums"`
-				} `json:"tracksToPlay"`
-				TracksToPlayWithAds []struct {
-					Type  string `json:"type"`
-					Track struct {
-						ID                       string `json:"id"`
-						RealID                   string `json:"realId"`
-						Title                    string `json:"title"`
-						DurationMs               int    `json:"durationMs"`
-						FileSize                 int    `json:"fileSize"`
-						Available                bool   `json:"available"`
-						AvailableForPremiumUsers bool   `json:"availableForPremiumUsers"`
-						LyricsAvailable          bool   `json:"lyricsAvailable"`
-						StorageDir               string `json:"storageDir"`
-						CoverURI                 string `json:"coverUri"`
-						OgImage                  string `json:"ogImage"`
-						Major                    struct {
-							ID   int    `json:"id"`
-							Name string `json:"name"`
-						} `json:"major"`
-						Normalization struct {
-							Gain float64 `json:"gain"`
-							Peak int     `json:"peak"`
-						} `json:"normalization"`
-						Artists []struct {
-							ID       int    `json:"id"`
-							Name     string `json:"name"`
-							Various  bool   `json:"various"`
-							Composer bool   `json:"composer"`
-							Cover    struct {
-								Type   string `json:"type"`
-								Prefix string `json:"prefix"`
-								URI    string `json:"uri"`
-							} `json:"cover"`
-							Genres []interface{} `json:"genres"`
-						} `json:"artists"`
-						Albums []struct {
-							ID                       int           `json:"id"`
-							Year                     int           `json:"year"`
-							TrackCount               int           `json:"trackCount"`
-							Recent                   bool          `json:"recent"`
-							VeryImportant            bool          `json:"veryImportant"`
-							Available                bool          `json:"available"`
-							AvailableForPremiumUsers bool          `json:"availableForPremiumUsers"`
-							AvailableForMobile       bool          `json:"availableForMobile"`
-							AvailablePartially       bool          `json:"availablePartially"`
-							Title                    string        `json:"title"`
-							Type                     string        `json:"type"`
-							CoverURI                 string        `json:"coverUri"`
-							OgImage                  string        `json:"ogImage"`
-							Genre                    string        `json:"genre"`
-							Bests                    []interface{} `json:"bests"`
-							Buy                      []interface{} `json:"buy"`
-							ReleaseDate              time.Time     `json:"releaseDate"`
-							Artists                  []struct {
-								ID       int    `json:"id"`
-								Name     string `json:"name"`
-								Various  bool   `json:"various"`
-								Composer bool   `json:"composer"`
-								Cover    struct {
-									Type   string `json:"type"`
-									Prefix string `json:"prefix"`
-									URI    string `json:"uri"`
-								} `json:"cover"`
-								Genres []interface{} `json:"genres"`
-							} `json:"artists"`
-							Labels []struct {
-								ID          int    `json:"id"`
-								Name        string `json:"name"`
-								Description string `json:"description"`
-								Image       string `json:"image"`
-							} `json:"labels"`
-							TrackPosition struct {
-								Volume int `json:"volume"`
-								Index  int `json:"index"`
-							} `json:"trackPosition"`
-						} `json:"albums"`
-					} `json:"track"`
-				} `json:"tracksToPlayWithAds"`
-			} `json:"days"`
+			// CanGetMoreEvents tells whether there are older days of feed
+			CanGetMoreEvents   bool                `json:"canGetMoreEvents"`
+			Pumpkin            bool                `json:"pumpkin"`
+			Today              string              `json:"today"`
+			GeneratedPlaylists []GeneratedPlaylist `json:"generatedPlaylists"`
+			Headlines          []FeedHeadline      `json:"headlines"`
+			Days               []FeedDay           `json:"days"`
 		} `json:"result"`
 	}
+	// FeedHeadline is headline of feed
+	FeedHeadline struct {
+		Type    string `json:"type"`
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	}
+	// FeedDay is a day of feed with its events, e.g. "2024-01-31"
+	FeedDay struct {
+		Day                 string        `json:"day"`
+		Events              []FeedEvent   `json:"events"`
+		TracksToPlay        []Track       `json:"tracksToPlay"`
+		TracksToPlayWithAds []FeedAdTrack `json:"tracksToPlayWithAds"`
+	}
+	// FeedAdTrack is track to play or ad between tracks
+	FeedAdTrack struct {
+		Type  string `json:"type"`
+		Track Track  `json:"track"`
+	}
+	// FeedEvent is event of feed day, e.g. new tracks of liked artists.
+	// Fields of the event are in the field named after its type, e.g.
+	// Tracks for FeedEventTracks. Data keeps the event to decode ones
+	// of types added later.
+	FeedEvent struct {
+		ID          string      `json:"id"`
+		Type        string      `json:"type"`
+		TypeForFrom string      `json:"typeForFrom,omitempty"`
+		Title       []FeedTitle `json:"title,omitempty"`
+
+		Tracks       *FeedTracksEvent       `json:"-"`
+		Artists      *FeedArtistsEvent      `json:"-"`
+		Albums       *FeedAlbumsEvent       `json:"-"`
+		GenreTop     *FeedGenreTopEvent     `json:"-"`
+		Promotion    *FeedPromotionEvent    `json:"-"`
+		Notification *FeedNotificationEvent `json:"-"`
+
+		// Data is the whole event as received
+		Data json.RawMessage `json:"-"`
+	}
+	// FeedTitle is part of event title, e.g. text or link to an artist
+	FeedTitle struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	// FeedTracksEvent is event of new tracks
+	FeedTracksEvent struct {
+		Tracks []Track `json:"tracks"`
+	}
+	// FeedArtistsEvent is event of artists recommended to the user
+	FeedArtistsEvent struct {
+		Artists []FeedArtist `json:"artists"`
+	}
+	// FeedArtist is artist of event with its tracks
+	FeedArtist struct {
+		Artist     Artist  `json:"artist"`
+		Subscribed bool    `json:"subscribed"`
+		Tracks     []Track `json:"tracks"`
+	}
+	// FeedAlbumsEvent is event of new albums
+	FeedAlbumsEvent struct {
+		Albums []FeedAlbum `json:"albums"`
+	}
+	// FeedAlbum is album of event with its tracks
+	FeedAlbum struct {
+		Album  Album   `json:"album"`
+		Tracks []Track `json:"tracks"`
+	}
+	// FeedGenreTopEvent is event of top tracks of genre
+	FeedGenreTopEvent struct {
+		Genre            string  `json:"genre"`
+		RadioIsAvailable bool    `json:"radioIsAvailable"`
+		Tracks           []Track `json:"tracks"`
+	}
+	// FeedPromotionEvent is event of promoted playlists
+	FeedPromotionEvent struct {
+		Promo FeedPromo `json:"promo"`
+	}
+	// FeedPromo is promotion of playlists
+	FeedPromo struct {
+		PromoID       string        `json:"promoId"`
+		Category      string        `json:"category"`
+		TitleURL      string        `json:"titleUrl"`
+		SubtitleURL   string        `json:"subtitleUrl"`
+		Title         string        `json:"title"`
+		Subtitle      string        `json:"subtitle"`
+		Heading       string        `json:"heading"`
+		Description   string        `json:"description"`
+		Background    string        `json:"background"`
+		ImagePosition string        `json:"imagePosition"`
+		PromotionType string        `json:"promotionType"`
+		Tags          []interface{} `json:"tags"`
+		StartDate     time.Time     `json:"startDate"`
+		Pager         struct {
+			Total   int `json:"total"`
+			Page    int `json:"page"`
+			PerPage int `json:"perPage"`
+		} `json:"pager"`
+		Playlists []FeedPromoPlaylist `json:"playlists"`
+	}
+	// FeedPromoPlaylist is promoted playlist with some of its artists
+	FeedPromoPlaylist struct {
+		Playlist struct {
+			PlaylistsResult
+			DescriptionFormatted string `json:"descriptionFormatted"`
+			BackgroundColor      string `json:"backgroundColor"`
+			TextColor            string `json:"textColor"`
+			Image                string `json:"image"`
+			Tags                 []struct {
+				ID    string `json:"id"`
+				Value string `json:"value"`
+			} `json:"tags"`
+		} `json:"playlist"`
+		SomeArtists []struct {
+			Various          bool     `json:"various"`
+			Composer         bool     `json:"composer"`
+			Available        bool     `json:"available"`
+			TicketsAvailable bool     `json:"ticketsAvailable"`
+			ID               string   `json:"id"`
+			Name             string   `json:"name"`
+			OgImage          string   `json:"ogImage"`
+			Genres           []string `json:"genres"`
+			Cover            struct {
+				Type   string `json:"type"`
+				Prefix string `json:"prefix"`
+				URI    string `json:"uri"`
+			} `json:"cover,omitempty"`
+			Counts struct {
+				Tracks       int `json:"tracks"`
+				DirectAlbums int `json:"directAlbums"`
+				AlsoAlbums   int `json:"alsoAlbums"`
+				AlsoTracks   int `json:"alsoTracks"`
+			} `json:"counts"`
+			Ratings struct {
+				Day   int `json:"day"`
+				Week  int `json:"week"`
+				Month int `json:"month"`
+			} `json:"ratings,omitempty"`
+			Links []struct {
+				Title         string `json:"title"`
+				Href          string `json:"href"`
+				Type          string `json:"type"`
+				SocialNetwork string `json:"socialNetwork,omitempty"`
+			} `json:"links"`
+		} `json:"someArtists"`
+		ArtistsCount int `json:"artistsCount"`
+	}
+	// FeedNotificationEvent is event of message to the user
+	FeedNotificationEvent struct {
+		Message string `json:"message"`
+	}
 )
 
+// UnmarshalJSON decodes event into the field matching its type
+func (e *FeedEvent) UnmarshalJSON(data []byte) error {
+	type event FeedEvent
+	if err := json.Unmarshal(data, (*event)(e)); err != nil {
+		return err
+	}
+	e.Data = append(json.RawMessage(nil), data...)
+
+	return decodeTagged(data, e.Type, map[string]any{
+		FeedEventTracks:       &e.Tracks,
+		FeedEventArtists:      &e.Artists,
+		FeedEventAlbums:       &e.Albums,
+		FeedEventGenreTop:     &e.GenreTop,
+		FeedEventPromotion:    &e.Promotion,
+		FeedEventNotification: &e.Notification,
+	})
+}
+
+// GeneratedPlaylist returns playlist generated for the user by type,
+// e.g. GeneratedPlaylistOfTheDay
+func (f *FeedResp) GeneratedPlaylist(typ string) (*GeneratedPlaylist, bool) {
+	for i, playlist := range f.Result.GeneratedPlaylists {
+		if playlist.Type == typ {
+			return &f.Result.GeneratedPlaylists[i], true
+		}
+	}
+	return nil, false
+}
+
 // Get returns feed of current user or base feed if there is no access token
 func (s *FeedService) Get(
 	ctx context.Context,
 ) (*FeedResp, *http.Response, error) {
-	req, err := s.client.NewRequest(http.MethodGet, "feed", nil)
+	return s.get(ctx, "")
+}
+
+// GetOlder returns days of feed older than day, e.g. "2024-01-31".
+// It's used to get more events if FeedResp.Result.CanGetMoreEvents is set.
+func (s *FeedService) GetOlder(
+	ctx context.Context,
+	day string,
+) (*FeedResp, *http.Response, error) {
+	return s.get(ctx, day)
+}
+
+// IterDays returns iterator over days of feed from today.
+// Older days are requested while there are more events.
+func (s *FeedService) IterDays(ctx context.Context) iter.Seq2[FeedDay, error] {
+	return func(yield func(FeedDay, error) bool) {
+		olderThan := ""
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(FeedDay{}, err)
+				return
+			}
+
+			feed, _, err := s.get(ctx, olderThan)
+			if err != nil {
+				yield(FeedDay{}, err)
+				return
+			}
+
+			days := feed.Result.Days
+			for _, day := range days {
+				if !yield(day, nil) {
+					return
+				}
+			}
+
+			if !feed.Result.CanGetMoreEvents || len(days) == 0 || days[len(days)-1].Day == olderThan {
+				return
+			}
+			olderThan = days[len(days)-1].Day
+		}
+	}
+}
+
+func (s *FeedService) get(
+	ctx context.Context,
+	olderThan string,
+) (*FeedResp, *http.Response, error) {
+	uri := "feed"
+	if olderThan != "" {
+		queryParams := url.Values{}
+		queryParams.Set("olderThan", olderThan)
+		uri += "?" + queryParams.Encode()
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
 	if err != nil {
 		return nil, nil, err
 	}
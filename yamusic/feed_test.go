@@ -30,3 +30,93 @@ func TestFeedService_Get(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
 }
+
+func TestFeedService_IterDays(t *testing.T) {
+	setup()
+	defer teardown()
+
+	pages := map[string]string{
+		"":           `{"result":{"canGetMoreEvents":true,"days":[{"day":"2026-10-17"},{"day":"2026-10-16"}]}}`,
+		"2026-10-16": `{"result":{"canGetMoreEvents":true,"days":[{"day":"2026-10-15"}]}}`,
+		"2026-10-15": `{"result":{"canGetMoreEvents":false,"days":[{"day":"2026-10-14"}]}}`,
+	}
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Query().Get("olderThan")]
+		assert.True(t, ok)
+		fmt.Fprint(w, page)
+	})
+
+	var days []string
+	for day, err := range client.Feed().IterDays(context.Background()) {
+		assert.NoError(t, err)
+		days = append(days, day.Day)
+	}
+
+	assert.Equal(t, []string{"2026-10-17", "2026-10-16", "2026-10-15", "2026-10-14"}, days)
+}
+
+func TestFeedEvent_UnmarshalJSON(t *testing.T) {
+	var feed FeedResp
+	err := json.Unmarshal([]byte(`{"result":{
+		"generatedPlaylists":[
+			{"type":"playlistOfTheDay","ready":true,"data":{"kind":1,"title":"Playlist of the Day"}},
+			{"type":"neverHeard","ready":true,"data":{"kind":2,"title":"DejaVu"}}
+		],
+		"days":[{"day":"2026-10-17","events":[
+			{"id":"1","type":"tracks","tracks":[{"id":"1","title":"Uprising"}]},
+			{"id":"2","type":"artists","artists":[{"artist":{"id":10,"name":"Muse"},"subscribed":true}]},
+			{"id":"3","type":"albums","albums":[{"album":{"id":10,"title":"The Resistance"}}]},
+			{"id":"4","type":"genre-top","genre":"rock","radioIsAvailable":true,"tracks":[{"id":"2"}]},
+			{"id":"5","type":"promotion","promo":{"promoId":"p","playlists":[{"playlist":{"kind":3,"textColor":"#fff"}}]}},
+			{"id":"6","type":"notification","message":"Welcome"},
+			{"id":"7","type":"concert","concert":{"city":"Moscow"}}
+		]}]
+	}}`), &feed)
+	assert.NoError(t, err)
+
+	playlist, ok := feed.GeneratedPlaylist(GeneratedDejaVu)
+	if assert.True(t, ok) {
+		assert.Equal(t, "DejaVu", playlist.Data.Title)
+	}
+	_, ok = feed.GeneratedPlaylist(GeneratedPremiere)
+	assert.False(t, ok)
+
+	if !assert.Len(t, feed.Result.Days, 1) || !assert.Len(t, feed.Result.Days[0].Events, 7) {
+		return
+	}
+	events := feed.Result.Days[0].Events
+
+	if assert.NotNil(t, events[0].Tracks) {
+		assert.Equal(t, "Uprising", events[0].Tracks.Tracks[0].Title)
+	}
+	assert.Nil(t, events[0].Artists)
+	if assert.NotNil(t, events[1].Artists) {
+		assert.Equal(t, "Muse", events[1].Artists.Artists[0].Artist.Name)
+		assert.True(t, events[1].Artists.Artists[0].Subscribed)
+	}
+	if assert.NotNil(t, events[2].Albums) {
+		assert.Equal(t, 10, events[2].Albums.Albums[0].Album.ID)
+	}
+	if assert.NotNil(t, events[3].GenreTop) {
+		assert.Equal(t, "rock", events[3].GenreTop.Genre)
+		assert.Len(t, events[3].GenreTop.Tracks, 1)
+	}
+	if assert.NotNil(t, events[4].Promotion) {
+		promoted := events[4].Promotion.Promo.Playlists[0].Playlist
+		assert.Equal(t, 3, promoted.Kind)
+		assert.Equal(t, "#fff", promoted.TextColor)
+	}
+	if assert.NotNil(t, events[5].Notification) {
+		assert.Equal(t, "Welcome", events[5].Notification.Message)
+	}
+
+	unknown := events[6]
+	assert.Equal(t, "concert", unknown.Type)
+	var concert struct {
+		Concert struct {
+			City string `json:"city"`
+		} `json:"concert"`
+	}
+	assert.NoError(t, json.Unmarshal(unknown.Data, &concert))
+	assert.Equal(t, "Moscow", concert.Concert.City)
+}
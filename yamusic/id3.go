@@ -0,0 +1,177 @@
+package yamusic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+)
+
+// ErrUnsupportedTag is returned when lyrics can't be embedded into ID3 tag
+// of a file, e.g. tag of version 2.2 or with unsynchronisation
+var ErrUnsupportedTag = ClientError("unsupported ID3 tag")
+
+const (
+	id3HeaderSize = 10
+	id3FrameSize  = 10
+	// Flags of ID3 tag header
+	id3Unsynchronisation = 0x80
+	id3ExtendedHeader    = 0x40
+	id3Footer            = 0x10
+)
+
+// embedLyrics writes lyrics in language into USLT frame of ID3v2 tag of file
+// by path replacing lyrics already there. Tag of version 2.3 is added
+// if file has no tag, other frames of existing tag are kept.
+func embedLyrics(path, lyrics, language string) error {
+	if language == "" {
+		language = "und"
+	}
+	if len(language) != 3 {
+		return fmt.Errorf("lyrics language %q is not ISO 639-2 code", language)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	version := byte(3)
+	var frames [][]byte
+	audio := data
+	if bytes.HasPrefix(data, []byte("ID3")) {
+		if len(data) < id3HeaderSize {
+			return fmt.Errorf("%s: %w: truncated header", path, ErrUnsupportedTag)
+		}
+		version = data[3]
+		flags := data[5]
+		if version != 3 && version != 4 {
+			return fmt.Errorf("%s: %w: version 2.%d", path, ErrUnsupportedTag, version)
+		}
+		if flags&(id3Unsynchronisation|id3ExtendedHeader) != 0 {
+			return fmt.Errorf("%s: %w: flags %#x", path, ErrUnsupportedTag, flags)
+		}
+
+		size := int(syncsafe(data[6:10]))
+		end := id3HeaderSize + size
+		if flags&id3Footer != 0 {
+			end += id3HeaderSize
+		}
+		if end > len(data) {
+			return fmt.Errorf("%s: %w: truncated tag", path, ErrUnsupportedTag)
+		}
+		frames, err = id3Frames(data[id3HeaderSize:id3HeaderSize+size], version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		audio = data[end:]
+	}
+
+	frames = append(frames, usltFrame(lyrics, language, version))
+
+	var body bytes.Buffer
+	for _, frame := range frames {
+		body.Write(frame)
+	}
+
+	var tag bytes.Buffer
+	tag.WriteString("ID3")
+	tag.Write([]byte{version, 0, 0})
+	tag.Write(toSyncsafe(uint32(body.Len())))
+	tag.Write(body.Bytes())
+	tag.Write(audio)
+
+	// File is replaced at once, so it's never left half written
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(info.Mode()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(tag.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// id3Frames returns raw frames of tag body except lyrics frames
+func id3Frames(body []byte, version byte) ([][]byte, error) {
+	var frames [][]byte
+	for len(body) >= id3FrameSize && body[0] != 0 {
+		size := int(binary.BigEndian.Uint32(body[4:8]))
+		if version == 4 {
+			size = int(syncsafe(body[4:8]))
+		}
+		if id3FrameSize+size > len(body) {
+			return nil, fmt.Errorf("%w: truncated frame %q", ErrUnsupportedTag, body[:4])
+		}
+		frame := body[:id3FrameSize+size]
+		body = body[id3FrameSize+size:]
+		if string(frame[:4]) != "USLT" {
+			frames = append(frames, frame)
+		}
+	}
+	return frames, nil
+}
+
+// usltFrame returns unsynchronised lyrics frame. Text is encoded in UTF-16
+// for version 2.3 and in UTF-8 for version 2.4.
+func usltFrame(lyrics, language string, version byte) []byte {
+	var content bytes.Buffer
+	if version == 4 {
+		content.WriteByte(3)
+		content.WriteString(language)
+		// Empty description
+		content.WriteByte(0)
+		content.WriteString(lyrics)
+	} else {
+		content.WriteByte(1)
+		content.WriteString(language)
+		content.Write(utf16WithBOM(""))
+		content.Write([]byte{0, 0})
+		content.Write(utf16WithBOM(lyrics))
+	}
+
+	var frame bytes.Buffer
+	frame.WriteString("USLT")
+	if version == 4 {
+		frame.Write(toSyncsafe(uint32(content.Len())))
+	} else {
+		frame.Write(binary.BigEndian.AppendUint32(nil, uint32(content.Len())))
+	}
+	frame.Write([]byte{0, 0})
+	frame.Write(content.Bytes())
+	return frame.Bytes()
+}
+
+// utf16WithBOM returns s encoded in little endian UTF-16 with byte order mark
+func utf16WithBOM(s string) []byte {
+	b := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// syncsafe decodes 4 bytes of syncsafe integer, 7 bits in every byte
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7f)<<21 | uint32(b[1]&0x7f)<<14 | uint32(b[2]&0x7f)<<7 | uint32(b[3]&0x7f)
+}
+
+// toSyncsafe encodes n as 4 bytes of syncsafe integer
+func toSyncsafe(n uint32) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
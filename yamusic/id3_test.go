@@ -0,0 +1,64 @@
+package yamusic
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedLyrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	assert.NoError(t, os.WriteFile(path, []byte("audio"), 0o644))
+
+	assert.NoError(t, embedLyrics(path, "Привет", ""))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	frame := usltFrame("Привет", "und", 3)
+	assert.Equal(t, []byte("ID3\x03\x00\x00"), data[:6])
+	assert.Equal(t, uint32(len(frame)), syncsafe(data[6:10]))
+	assert.Equal(t, frame, data[10:10+len(frame)])
+	assert.Equal(t, "audio", string(data[10+len(frame):]))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
+
+func TestEmbedLyricsReplacesFrame(t *testing.T) {
+	title := []byte("TIT2\x00\x00\x00\x06\x00\x00\x03Title")
+	old := usltFrame("old", "eng", 4)
+	var body bytes.Buffer
+	body.Write(title)
+	body.Write(old)
+	// Padding
+	body.Write(make([]byte, 16))
+
+	var file bytes.Buffer
+	file.WriteString("ID3\x04\x00\x00")
+	file.Write(toSyncsafe(uint32(body.Len())))
+	file.Write(body.Bytes())
+	file.WriteString("audio")
+
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	assert.NoError(t, os.WriteFile(path, file.Bytes(), 0o644))
+
+	assert.NoError(t, embedLyrics(path, "new", "eng"))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	frames, err := id3Frames(data[10:10+syncsafe(data[6:10])], 4)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{title}, frames)
+	assert.True(t, bytes.HasSuffix(data, append(usltFrame("new", "eng", 4), "audio"...)))
+}
+
+func TestEmbedLyricsUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	assert.NoError(t, os.WriteFile(path, []byte("ID3\x02\x00\x00\x00\x00\x00\x00audio"), 0o644))
+
+	assert.ErrorIs(t, embedLyrics(path, "lyrics", ""), ErrUnsupportedTag)
+}
@@ -0,0 +1,303 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Types of landing block entities
+const (
+	LandingPersonalPlaylist = "personal-playlist"
+	LandingPromotion        = "promotion"
+	LandingAlbum            = "album"
+	LandingPlaylist         = "playlist"
+	LandingChartItem        = "chart-item"
+	LandingPlayContext      = "play-context"
+	LandingMixLink          = "mix-link"
+)
+
+// landingBlocks are blocks requested if none are given
+var landingBlocks = []string{
+	"personalplaylists", "promotions", "new-releases", "new-playlists",
+	"mixes", "chart", "artists", "albums", "playlists", "play_contexts",
+}
+
+type (
+	// LandingService is a service to deal with landing page, charts
+	// and new releases
+	LandingService struct {
+		client *Client
+	}
+	// LandingResp describes get landing blocks response
+	LandingResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Pumpkin   bool           `json:"pumpkin"`
+			ContentID string         `json:"contentId"`
+			Blocks    []LandingBlock `json:"blocks"`
+		} `json:"result"`
+	}
+	// LandingBlock is block of landing page, e.g. chart or new releases
+	LandingBlock struct {
+		ID          string          `json:"id"`
+		Type        string          `json:"type"`
+		TypeForFrom string          `json:"typeForFrom"`
+		Title       string          `json:"title"`
+		Description string          `json:"description"`
+		Entities    []LandingEntity `json:"entities"`
+	}
+	// LandingEntity is item of landing block, e.g. album or promotion.
+	// Data is decoded into the field of entity's type, e.g. Album for
+	// LandingAlbum entity.
+	LandingEntity struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+
+		PersonalPlaylist *GeneratedPlaylist `json:"-"`
+		Promotion        *Promotion         `json:"-"`
+		Album            *Album             `json:"-"`
+		Playlist         *PlaylistsResult   `json:"-"`
+		ChartItem        *ChartItem         `json:"-"`
+		PlayContext      *PlayContext       `json:"-"`
+		MixLink          *MixLink           `json:"-"`
+
+		Data json.RawMessage `json:"data"`
+	}
+	// GeneratedPlaylist is playlist generated for the user, e.g. Playlist
+	// of the Day
+	GeneratedPlaylist struct {
+		Type   string             `json:"type"`
+		Ready  bool               `json:"ready"`
+		Notify bool               `json:"notify"`
+		Data   PlaylistWithTracks `json:"data"`
+	}
+	// Promotion is promo banner
+	Promotion struct {
+		PromoID   string `json:"promoId"`
+		Title     string `json:"title"`
+		Subtitle  string `json:"subtitle"`
+		Heading   string `json:"heading"`
+		URL       string `json:"url"`
+		URLScheme string `json:"urlScheme"`
+		TextColor string `json:"textColor"`
+		Gradient  string `json:"gradient"`
+		Image     string `json:"image"`
+	}
+	// ChartItem is track with its place in chart
+	ChartItem struct {
+		Track Track         `json:"track"`
+		Chart ChartPosition `json:"chart"`
+	}
+	// ChartPosition is place of track in chart
+	ChartPosition struct {
+		Position int `json:"position"`
+		// Progress is "up", "down", "same" or "new"
+		Progress  string `json:"progress"`
+		Listeners int    `json:"listeners"`
+		// Shift is change of position since the last chart
+		Shift   int    `json:"shift"`
+		BgColor string `json:"bgColor"`
+	}
+	// PlayContext is something the user has played recently
+	PlayContext struct {
+		Client      string `json:"client_"`
+		Context     string `json:"context"`
+		ContextItem string `json:"contextItem"`
+		Tracks      []struct {
+			TrackID   string    `json:"trackId"`
+			AlbumID   string    `json:"albumId"`
+			Timestamp time.Time `json:"timestamp"`
+		} `json:"tracks"`
+	}
+	// MixLink is link to a selection of playlists, e.g. by mood
+	MixLink struct {
+		Title              string `json:"title"`
+		URL                string `json:"url"`
+		URLScheme          string `json:"urlScheme"`
+		TextColor          string `json:"textColor"`
+		BackgroundColor    string `json:"backgroundColor"`
+		BackgroundImageURI string `json:"backgroundImageUri"`
+		CoverURI           string `json:"coverUri"`
+		CoverWhite         string `json:"coverWhite"`
+	}
+	// ChartResp describes get chart response
+	ChartResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			ID               string `json:"id"`
+			Type             string `json:"type"`
+			TypeForFrom      string `json:"typeForFrom"`
+			Title            string `json:"title"`
+			ChartDescription string `json:"chartDescription"`
+			Menu             struct {
+				Items []struct {
+					Title    string `json:"title"`
+					URL      string `json:"url"`
+					Selected bool   `json:"selected"`
+				} `json:"items"`
+			} `json:"menu"`
+			Chart ChartPlaylist `json:"chart"`
+		} `json:"result"`
+	}
+	// ChartPlaylist is playlist of chart tracks
+	ChartPlaylist struct {
+		PlaylistsResult
+		Tracks []ChartTrack `json:"tracks"`
+	}
+	// ChartTrack is track of chart playlist with its place in chart
+	ChartTrack struct {
+		ID        int           `json:"id"`
+		Timestamp time.Time     `json:"timestamp"`
+		Track     Track         `json:"track"`
+		Chart     ChartPosition `json:"chart"`
+	}
+	// NewReleasesResp describes get new releases response
+	NewReleasesResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			ID          string `json:"id"`
+			Type        string `json:"type"`
+			TypeForFrom string `json:"typeForFrom"`
+			Title       string `json:"title"`
+			// NewReleases are ids of new albums
+			NewReleases []int `json:"newReleases"`
+		} `json:"result"`
+	}
+	// NewPlaylistsResp describes get new playlists response
+	NewPlaylistsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			ID           string `json:"id"`
+			Type         string `json:"type"`
+			TypeForFrom  string `json:"typeForFrom"`
+			Title        string `json:"title"`
+			NewPlaylists []struct {
+				UID  int `json:"uid"`
+				Kind int `json:"kind"`
+			} `json:"newPlaylists"`
+		} `json:"result"`
+	}
+)
+
+// UnmarshalJSON decodes entity's data into the field matching its type
+func (e *LandingEntity) UnmarshalJSON(data []byte) error {
+	type entity LandingEntity
+	if err := json.Unmarshal(data, (*entity)(e)); err != nil {
+		return err
+	}
+	if len(e.Data) == 0 {
+		return nil
+	}
+
+	return decodeTagged(e.Data, e.Type, map[string]any{
+		LandingPersonalPlaylist: &e.PersonalPlaylist,
+		LandingPromotion:        &e.Promotion,
+		LandingAlbum:            &e.Album,
+		LandingPlaylist:         &e.Playlist,
+		LandingChartItem:        &e.ChartItem,
+		LandingPlayContext:      &e.PlayContext,
+		LandingMixLink:          &e.MixLink,
+	})
+}
+
+// TracksByPosition returns tracks of chart from the first place
+func (p *ChartPlaylist) TracksByPosition() []Track {
+	chart := slices.Clone(p.Tracks)
+	slices.SortStableFunc(chart, func(a, b ChartTrack) int {
+		return a.Chart.Position - b.Chart.Position
+	})
+
+	tracks := make([]Track, 0, len(chart))
+	for _, track := range chart {
+		tracks = append(tracks, track.Track)
+	}
+	return tracks
+}
+
+// GetBlocks returns blocks of landing page by their types,
+// e.g. "chart" or "new-releases". All known blocks are returned
+// if none are given.
+func (s *LandingService) GetBlocks(ctx context.Context, blocks ...string) (*LandingResp, *http.Response, error) {
+	if len(blocks) == 0 {
+		blocks = landingBlocks
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("blocks", strings.Join(blocks, ","))
+
+	req, err := s.client.NewRequest(http.MethodGet, "landing3?"+queryParams.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	landing := new(LandingResp)
+	resp, err := s.client.Do(ctx, req, landing)
+	return landing, resp, err
+}
+
+// GetChart returns chart by option, e.g. "world" or "russia".
+// Default chart is returned if option is empty.
+func (s *LandingService) GetChart(ctx context.Context, option string) (*ChartResp, *http.Response, error) {
+	uri := "landing3/chart"
+	if option != "" {
+		uri += "/" + url.PathEscape(option)
+	}
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chart := new(ChartResp)
+	resp, err := s.client.Do(ctx, req, chart)
+	return chart, resp, err
+}
+
+// GetNewReleases returns ids of new albums
+func (s *LandingService) GetNewReleases(ctx context.Context) (*NewReleasesResp, *http.Response, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "landing3/new-releases", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	releases := new(NewReleasesResp)
+	resp, err := s.client.Do(ctx, req, releases)
+	return releases, resp, err
+}
+
+// GetNewReleaseAlbums returns new albums
+func (s *LandingService) GetNewReleaseAlbums(ctx context.Context) (Albums, error) {
+	releases, _, err := s.GetNewReleases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases.Result.NewReleases) == 0 {
+		return nil, nil
+	}
+
+	albums, _, err := s.client.albums.GetAll(ctx, releases.Result.NewReleases)
+	if err != nil {
+		return nil, err
+	}
+	return albums.Result, nil
+}
+
+// GetNewPlaylists returns ids of new playlists
+func (s *LandingService) GetNewPlaylists(ctx context.Context) (*NewPlaylistsResp, *http.Response, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "landing3/new-playlists", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	playlists := new(NewPlaylistsResp)
+	resp, err := s.client.Do(ctx, req, playlists)
+	return playlists, resp, err
+}
@@ -0,0 +1,104 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLandingService_GetBlocks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/landing3", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "chart,promotions", r.URL.Query().Get("blocks"))
+		fmt.Fprint(w, `{"result":{"contentId":"c1","blocks":[
+			{"id":"b1","type":"chart","title":"Chart","entities":[
+				{"id":"e1","type":"chart-item","data":{"track":{"id":"1"},"chart":{"position":1,"progress":"up","shift":2}}}
+			]},
+			{"id":"b2","type":"promotions","entities":[
+				{"id":"e2","type":"promotion","data":{"promoId":"p1","title":"Promo"}},
+				{"id":"e3","type":"podcast","data":{"id":5}}
+			]}
+		]}}`)
+	})
+
+	landing, _, err := client.Landing().GetBlocks(context.Background(), "chart", "promotions")
+
+	assert.NoError(t, err)
+	if !assert.Len(t, landing.Result.Blocks, 2) {
+		return
+	}
+	chart := landing.Result.Blocks[0].Entities[0]
+	if assert.NotNil(t, chart.ChartItem) {
+		assert.Equal(t, "1", chart.ChartItem.Track.ID)
+		assert.Equal(t, ChartPosition{Position: 1, Progress: "up", Shift: 2}, chart.ChartItem.Chart)
+	}
+	assert.Nil(t, chart.Promotion)
+
+	promo := landing.Result.Blocks[1].Entities[0]
+	if assert.NotNil(t, promo.Promotion) {
+		assert.Equal(t, "Promo", promo.Promotion.Title)
+	}
+	unknown := landing.Result.Blocks[1].Entities[1]
+	assert.JSONEq(t, `{"id":5}`, string(unknown.Data))
+}
+
+func TestLandingService_GetChart(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/landing3/chart/world", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"id":"chart","chart":{"uid":414787002,"kind":1076,"tracks":[
+			{"id":1,"track":{"id":"1"},"chart":{"position":2}},
+			{"id":2,"track":{"id":"2"},"chart":{"position":1}}
+		]}}}`)
+	})
+
+	chart, _, err := client.Landing().GetChart(context.Background(), "world")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1076, chart.Result.Chart.Kind)
+	tracks := chart.Result.Chart.TracksByPosition()
+	if assert.Len(t, tracks, 2) {
+		assert.Equal(t, "2", tracks[0].ID)
+	}
+}
+
+func TestLandingService_GetNewReleaseAlbums(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/landing3/new-releases", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"id":"new-releases","newReleases":[10,20]}}`)
+	})
+	mux.HandleFunc("/albums", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "10,20", r.FormValue("album-ids"))
+		fmt.Fprint(w, `{"result":[{"id":10},{"id":20}]}`)
+	})
+
+	albums, err := client.Landing().GetNewReleaseAlbums(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, albums, 2)
+}
+
+func TestLandingService_GetNewPlaylists(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/landing3/new-playlists", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"id":"new-playlists","newPlaylists":[{"uid":1,"kind":3}]}}`)
+	})
+
+	playlists, _, err := client.Landing().GetNewPlaylists(context.Background())
+
+	assert.NoError(t, err)
+	if assert.Len(t, playlists.Result.NewPlaylists, 1) {
+		assert.Equal(t, 3, playlists.Result.NewPlaylists[0].Kind)
+	}
+}
@@ -0,0 +1,288 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LikeType is a type of object that can be liked or disliked
+type LikeType string
+
+// Types of objects that can be liked or disliked
+const (
+	LikeTrack    LikeType = "track"
+	LikeAlbum    LikeType = "album"
+	LikeArtist   LikeType = "artist"
+	LikePlaylist LikeType = "playlist"
+)
+
+type (
+	// LikesService is a service to deal with likes and dislikes
+	LikesService struct {
+		client *Client
+	}
+	// LikesChangeResp describes like, unlike, dislike and undislike response
+	LikesChangeResp struct {
+		InvocationInfo InvocationInfo  `json:"invocationInfo"`
+		Error          Error           `json:"error"`
+		Result         LibraryRevision `json:"result"`
+	}
+	// LibraryRevision is revision of user's library after change. Only
+	// changes of tracks have revision, other changes are answered with "ok".
+	LibraryRevision struct {
+		Revision int `json:"revision"`
+	}
+	// LikedAlbumsResp describes get liked albums response
+	LikedAlbumsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         []LikedAlbum   `json:"result"`
+	}
+	// LikedAlbum is liked album with time of like
+	LikedAlbum struct {
+		ID        int       `json:"id"`
+		Timestamp time.Time `json:"timestamp"`
+		Album     Album     `json:"album"`
+	}
+	// LikedArtistsResp describes get liked artists response
+	LikedArtistsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         []LikedArtist  `json:"result"`
+	}
+	// LikedArtist is liked artist with time of like
+	LikedArtist struct {
+		Timestamp time.Time `json:"timestamp"`
+		Artist    Artist    `json:"artist"`
+	}
+	// LikedPlaylistsResp describes get liked playlists response
+	LikedPlaylistsResp struct {
+		InvocationInfo InvocationInfo  `json:"invocationInfo"`
+		Error          Error           `json:"error"`
+		Result         []LikedPlaylist `json:"result"`
+	}
+	// LikedPlaylist is liked playlist with time of like
+	LikedPlaylist struct {
+		Timestamp time.Time       `json:"timestamp"`
+		Playlist  PlaylistsResult `json:"playlist"`
+	}
+	// LikesSnapshot is a state of liked tracks saved by caller between syncs.
+	// It can be stored as JSON.
+	LikesSnapshot struct {
+		Revision int         `json:"revision"`
+		Tracks   []TrackLike `json:"tracks"`
+	}
+	// LikesDiff describes changes of liked tracks between two snapshots
+	LikesDiff struct {
+		PrevRevision int
+		Revision     int
+		Added        []TrackLike
+		Removed      []TrackLike
+	}
+)
+
+// UnmarshalJSON decodes revision. Bare "ok" result is decoded
+// as zero revision.
+func (r *LibraryRevision) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*r = LibraryRevision{}
+		return nil
+	}
+	type revision LibraryRevision
+	return json.Unmarshal(data, (*revision)(r))
+}
+
+// PlaylistLikeID returns id of playlist to like or dislike
+func PlaylistLikeID(uid, kind int) string {
+	return fmt.Sprintf("%d:%d", uid, kind)
+}
+
+// Like adds objects of type by ids to liked ones of the user.
+// Playlists are identified by PlaylistLikeID.
+func (s *LikesService) Like(
+	ctx context.Context,
+	typ LikeType,
+	ids []string,
+) (*LikesChangeResp, *http.Response, error) {
+	return s.change(ctx, "likes", typ, "add-multiple", ids)
+}
+
+// Unlike removes objects of type by ids from liked ones of the user
+func (s *LikesService) Unlike(
+	ctx context.Context,
+	typ LikeType,
+	ids []string,
+) (*LikesChangeResp, *http.Response, error) {
+	return s.change(ctx, "likes", typ, "remove", ids)
+}
+
+// Dislike adds objects of type by ids to disliked ones of the user
+func (s *LikesService) Dislike(
+	ctx context.Context,
+	typ LikeType,
+	ids []string,
+) (*LikesChangeResp, *http.Response, error) {
+	return s.change(ctx, "dislikes", typ, "add-multiple", ids)
+}
+
+// Undislike removes objects of type by ids from disliked ones of the user
+func (s *LikesService) Undislike(
+	ctx context.Context,
+	typ LikeType,
+	ids []string,
+) (*LikesChangeResp, *http.Response, error) {
+	return s.change(ctx, "dislikes", typ, "remove", ids)
+}
+
+// GetTracks returns liked tracks of the user. If library revision isn't
+// newer than sinceRevision, API may answer without tracks.
+func (s *LikesService) GetTracks(
+	ctx context.Context,
+	sinceRevision int,
+) (*LikeTracksResp, *http.Response, error) {
+	return s.getTracks(ctx, "likes", sinceRevision)
+}
+
+// GetDislikedTracks returns disliked tracks of the user. If library revision
+// isn't newer than sinceRevision, API may answer without tracks.
+func (s *LikesService) GetDislikedTracks(
+	ctx context.Context,
+	sinceRevision int,
+) (*LikeTracksResp, *http.Response, error) {
+	return s.getTracks(ctx, "dislikes", sinceRevision)
+}
+
+// GetAlbums returns liked albums of the user
+func (s *LikesService) GetAlbums(ctx context.Context) (*LikedAlbumsResp, *http.Response, error) {
+	uri := fmt.Sprintf("users/%v/likes/albums?rich=true", s.client.userID)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	albums := new(LikedAlbumsResp)
+	resp, err := s.client.Do(ctx, req, albums)
+	return albums, resp, err
+}
+
+// GetArtists returns liked artists of the user
+func (s *LikesService) GetArtists(ctx context.Context) (*LikedArtistsResp, *http.Response, error) {
+	uri := fmt.Sprintf("users/%v/likes/artists?with-timestamps=true", s.client.userID)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artists := new(LikedArtistsResp)
+	resp, err := s.client.Do(ctx, req, artists)
+	return artists, resp, err
+}
+
+// GetPlaylists returns liked playlists of the user
+func (s *LikesService) GetPlaylists(ctx context.Context) (*LikedPlaylistsResp, *http.Response, error) {
+	uri := fmt.Sprintf("users/%v/likes/playlists", s.client.userID)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	playlists := new(LikedPlaylistsResp)
+	resp, err := s.client.Do(ctx, req, playlists)
+	return playlists, resp, err
+}
+
+// SyncTracks returns changes of liked tracks since prev snapshot and the
+// new snapshot to pass to the next sync. All current liked tracks are
+// reported as added if prev is nil.
+func (s *LikesService) SyncTracks(
+	ctx context.Context,
+	prev *LikesSnapshot,
+) (*LikesDiff, *LikesSnapshot, error) {
+	if prev == nil {
+		prev = &LikesSnapshot{}
+	}
+
+	likes, _, err := s.GetTracks(ctx, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	library := likes.Result.Library
+
+	next := &LikesSnapshot{Revision: library.Revision, Tracks: library.Tracks}
+	diff := &LikesDiff{PrevRevision: prev.Revision, Revision: library.Revision}
+	if prev.Revision != 0 && prev.Revision == library.Revision {
+		return diff, next, nil
+	}
+
+	before := make(map[string]bool, len(prev.Tracks))
+	for _, track := range prev.Tracks {
+		before[track.ID] = true
+	}
+	after := make(map[string]bool, len(next.Tracks))
+	for _, track := range next.Tracks {
+		after[track.ID] = true
+		if !before[track.ID] {
+			diff.Added = append(diff.Added, track)
+		}
+	}
+	for _, track := range prev.Tracks {
+		if !after[track.ID] {
+			diff.Removed = append(diff.Removed, track)
+		}
+	}
+
+	s.client.logger.Info("liked tracks synced",
+		"prev_revision", diff.PrevRevision,
+		"revision", diff.Revision,
+		"added", len(diff.Added),
+		"removed", len(diff.Removed),
+	)
+
+	return diff, next, nil
+}
+
+func (s *LikesService) change(
+	ctx context.Context,
+	list string,
+	typ LikeType,
+	action string,
+	ids []string,
+) (*LikesChangeResp, *http.Response, error) {
+	form := url.Values{}
+	form.Set(string(typ)+"-ids", strings.Join(ids, ","))
+
+	uri := fmt.Sprintf("users/%v/%s/%ss/%s", s.client.userID, list, typ, action)
+	req, err := s.client.NewRequest(http.MethodPost, uri, form)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed := new(LikesChangeResp)
+	resp, err := s.client.Do(withRetrySafe(ctx), req, changed)
+	return changed, resp, err
+}
+
+func (s *LikesService) getTracks(
+	ctx context.Context,
+	list string,
+	sinceRevision int,
+) (*LikeTracksResp, *http.Response, error) {
+	queryParams := url.Values{}
+	queryParams.Set("if-modified-since-revision", strconv.Itoa(sinceRevision))
+
+	uri := fmt.Sprintf("users/%v/%s/tracks?%v", s.client.userID, list, queryParams.Encode())
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracks := new(LikeTracksResp)
+	resp, err := s.client.Do(ctx, req, tracks)
+	return tracks, resp, err
+}
@@ -0,0 +1,78 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikesService_Change(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/users/%v/likes/tracks/add-multiple", userID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "1:10,2", r.FormValue("track-ids"))
+		fmt.Fprint(w, `{"result":{"revision":5}}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/dislikes/artists/remove", userID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "3", r.FormValue("artist-ids"))
+		fmt.Fprint(w, `{"result":"ok"}`)
+	})
+
+	liked, _, err := client.Likes().Like(context.Background(), LikeTrack, []string{"1:10", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, liked.Result.Revision)
+
+	undisliked, _, err := client.Likes().Undislike(context.Background(), LikeArtist, []string{"3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, undisliked.Result.Revision)
+}
+
+func TestLikesService_GetTracks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/users/%v/dislikes/tracks", userID), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "3", r.URL.Query().Get("if-modified-since-revision"))
+		fmt.Fprint(w, `{"result":{"library":{"uid":1,"revision":4,"tracks":[{"id":"1","albumId":"10"}]}}}`)
+	})
+
+	disliked, _, err := client.Likes().GetDislikedTracks(context.Background(), 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, disliked.Result.Library.Revision)
+	assert.Len(t, disliked.Result.Library.Tracks, 1)
+}
+
+func TestLikesService_SyncTracks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	library := `{"result":{"library":{"revision":2,"tracks":[{"id":"3"},{"id":"1"}]}}}`
+	mux.HandleFunc(fmt.Sprintf("/users/%v/likes/tracks", userID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, library)
+	})
+
+	prev := &LikesSnapshot{Revision: 1, Tracks: []TrackLike{{ID: "1"}, {ID: "2"}}}
+	diff, next, err := client.Likes().SyncTracks(context.Background(), prev)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, diff.PrevRevision)
+	assert.Equal(t, 2, diff.Revision)
+	assert.Equal(t, []TrackLike{{ID: "3"}}, diff.Added)
+	assert.Equal(t, []TrackLike{{ID: "2"}}, diff.Removed)
+	assert.Equal(t, 2, next.Revision)
+
+	// Nothing has changed since the last sync
+	diff, _, err = client.Likes().SyncTracks(context.Background(), next)
+	assert.NoError(t, err)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+}
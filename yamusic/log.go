@@ -0,0 +1,181 @@
+package yamusic
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type (
+	// LogFilesOptions configures rotating log files
+	LogFilesOptions struct {
+		// Name of the current log file. Default is yamusic.log.
+		Name string
+		// MaxSize is size in bytes after which the file is rotated.
+		// Default is 10MB.
+		MaxSize int64
+		// MaxBackups is number of rotated files to keep. Default is 5.
+		MaxBackups int
+	}
+	// RotatingFile is an io.WriteCloser writing to a file that is rotated
+	// when it grows over the size limit. Rotated files get suffixes .1, .2
+	// and so on, the bigger suffix the older file.
+	RotatingFile struct {
+		mu   sync.Mutex
+		path string
+		opts LogFilesOptions
+		file *os.File
+		size int64
+	}
+	// debugLeveler is a minimal logging level of the default logger which
+	// depends on Client.Debug
+	debugLeveler struct {
+		client *Client
+	}
+)
+
+// Logger sets structured logger for Yandex.Music client.
+// By default messages are written to stdout and debug ones are written only
+// if Client.Debug is set.
+func Logger(logger *slog.Logger) func(*Client) {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// LogFiles makes Yandex.Music client write JSON logs to rotating files
+// in the log directory from config. It's ignored if Logger is set.
+func LogFiles(opts *LogFilesOptions) func(*Client) {
+	return func(c *Client) {
+		if opts == nil {
+			opts = &LogFilesOptions{}
+		}
+		c.logFiles = opts
+	}
+}
+
+// newDefaultLogger returns logger writing text messages to stdout
+func newDefaultLogger(c *Client) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: debugLeveler{client: c},
+	}))
+}
+
+func (l debugLeveler) Level() slog.Level {
+	if l.client.Debug {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// Logger returns logger of the client
+func (c *Client) Logger() *slog.Logger {
+	return c.logger
+}
+
+// NewRotatingFile opens log file in dir for appending. The directory is
+// created if it doesn't exist. If opts is nil, default options are used.
+func NewRotatingFile(dir string, opts *LogFilesOptions) (*RotatingFile, error) {
+	if opts == nil {
+		opts = &LogFilesOptions{}
+	}
+
+	r := &RotatingFile{opts: *opts}
+	if r.opts.Name == "" {
+		r.opts.Name = "yamusic.log"
+	}
+	if r.opts.MaxSize <= 0 {
+		r.opts.MaxSize = 10 << 20
+	}
+	if r.opts.MaxBackups <= 0 {
+		r.opts.MaxBackups = 5
+	}
+	r.path = filepath.Join(dir, r.opts.Name)
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write writes p to the current file rotating it beforehand
+// if p doesn't fit into size limit
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.size > 0 && r.size+int64(len(p)) > r.opts.MaxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate shifts backups by one dropping the oldest one
+// and starts a new file
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	backup := func(i int) string { return fmt.Sprintf("%s.%d", r.path, i) }
+
+	os.Remove(backup(r.opts.MaxBackups))
+	for i := r.opts.MaxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backup(i), backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, backup(1)); err != nil {
+		return err
+	}
+
+	return r.open()
+}
@@ -0,0 +1,84 @@
+package yamusic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Logger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	setup(Logger(logger))
+	defer teardown()
+
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":[]}`)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, _, err := client.Genres().List(context.Background())
+	assert.NoError(t, err)
+	_, _, err = client.Feed().Get(context.Background())
+	assert.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var record struct {
+		Level    string  `json:"level"`
+		Method   string  `json:"method"`
+		Endpoint string  `json:"endpoint"`
+		Status   int     `json:"status"`
+		Duration float64 `json:"duration"`
+		Error    string  `json:"error"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "DEBUG", record.Level)
+	assert.Equal(t, http.MethodGet, record.Method)
+	assert.Equal(t, "/genres", record.Endpoint)
+	assert.Equal(t, http.StatusOK, record.Status)
+
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "WARN", record.Level)
+	assert.Equal(t, "/feed", record.Endpoint)
+	assert.Equal(t, http.StatusNotFound, record.Status)
+	assert.NotEmpty(t, record.Error)
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	file, err := NewRotatingFile(dir, &LogFilesOptions{MaxSize: 10, MaxBackups: 2})
+	assert.NoError(t, err)
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := file.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(b)
+	}
+
+	assert.Equal(t, "fourth\n", read("yamusic.log"))
+	assert.Equal(t, "third\n", read("yamusic.log.1"))
+	assert.Equal(t, "second\n", read("yamusic.log.2"))
+	_, err = os.Stat(filepath.Join(dir, "yamusic.log.3"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,227 @@
+package yamusic
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LyricsFormat is a format of track lyrics
+type LyricsFormat string
+
+// Formats of track lyrics
+const (
+	// LyricsLRC is lyrics synchronised with track by time tags
+	LyricsLRC LyricsFormat = "LRC"
+	// LyricsText is plain text lyrics
+	LyricsText LyricsFormat = "TEXT"
+)
+
+// lyricsSignKey is a key of lyrics requests signature, the same as the
+// official clients use
+const lyricsSignKey = "p93jhgh689SBReK6ghtw62"
+
+// lrcTimeTag matches time tags of LRC lines, e.g. [01:02.34]
+var lrcTimeTag = regexp.MustCompile(`\[\d+:\d+(?:[.:]\d+)?\]`)
+
+// lrcMetaTag matches LRC metadata lines, e.g. [ar: Artist]
+var lrcMetaTag = regexp.MustCompile(`^\[[a-z]+:.*\]$`)
+
+type (
+	// LyricsResp describes get track lyrics response
+	LyricsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			// DownloadURL is link to lyrics file in the storage
+			DownloadURL     string   `json:"downloadUrl"`
+			LyricID         int      `json:"lyricId"`
+			ExternalLyricID string   `json:"externalLyricId"`
+			Writers         []string `json:"writers"`
+			Major           struct {
+				ID         int    `json:"id"`
+				Name       string `json:"name"`
+				PrettyName string `json:"prettyName"`
+			} `json:"major"`
+		} `json:"result"`
+	}
+	// LyricsOptions are options of saving lyrics of downloaded tracks
+	LyricsOptions struct {
+		// Embed sets writing lyrics into ID3 tag of downloaded mp3 files
+		// besides lyrics files
+		Embed bool
+		// Language is ISO 639-2 code of lyrics language in tag.
+		// Default is "und" (undetermined).
+		Language string
+	}
+)
+
+// Lyrics sets options of saving lyrics of downloaded tracks.
+// If opts is nil, default options are used.
+func Lyrics(opts *LyricsOptions) func(*Client) {
+	return func(c *Client) {
+		if opts == nil {
+			opts = &LyricsOptions{}
+		}
+		c.lyrics = opts
+	}
+}
+
+// GetLyrics returns link to lyrics of track by id in format
+func (t *TracksService) GetLyrics(
+	ctx context.Context,
+	id string,
+	format LyricsFormat,
+) (*LyricsResp, *http.Response, error) {
+	// Track id may be given with album id as trackID:albumID
+	id, _, _ = strings.Cut(id, ":")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	queryParams := url.Values{}
+	queryParams.Set("format", string(format))
+	queryParams.Set("timeStamp", timestamp)
+	queryParams.Set("sign", lyricsSign(id, timestamp))
+
+	uri := fmt.Sprintf("tracks/%v/lyrics?%v", id, queryParams.Encode())
+	req, err := t.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lyrics := new(LyricsResp)
+	resp, err := t.client.Do(ctx, req, lyrics)
+	return lyrics, resp, err
+}
+
+// DownloadLyrics returns lyrics of track by id in format
+func (t *TracksService) DownloadLyrics(ctx context.Context, id string, format LyricsFormat) (string, error) {
+	lyrics, _, err := t.GetLyrics(ctx, id, format)
+	if err != nil {
+		return "", err
+	}
+	if lyrics.Result.DownloadURL == "" {
+		return "", fmt.Errorf("lyrics of track %s: %w", id, ErrNotFound)
+	}
+
+	var buf bytes.Buffer
+	err = t.getStorage(ctx, lyrics.Result.DownloadURL, buf.ReadFrom)
+	return buf.String(), err
+}
+
+// downloadLyrics saves lyrics of track downloaded into file. Synchronised
+// lyrics are saved as .lrc next to file, otherwise plain text lyrics are
+// saved as .txt into lyricsDir. Tracks without lyrics are skipped.
+func (t *TracksService) downloadLyrics(ctx context.Context, track Track, file, lyricsDir string) error {
+	var lyrics string
+	var err error
+	synced := track.LyricsInfo.HasAvailableSyncLyrics
+	if synced {
+		lyrics, err = t.DownloadLyrics(ctx, track.ID, LyricsLRC)
+		if errors.Is(err, ErrNotFound) {
+			t.client.logger.Warn("synced lyrics not found, fall back to text", "track_id", track.ID)
+			synced = false
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if !synced {
+		switch {
+		case track.LyricsInfo.HasAvailableTextLyrics:
+			lyrics, err = t.DownloadLyrics(ctx, track.ID, LyricsText)
+		case track.LyricsAvailable:
+			var supplement *Supplement
+			supplement, _, err = t.GetSupplement(ctx, track.ID)
+			if err == nil {
+				lyrics = supplement.Result.Lyrics.FullLyrics
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	lyricsFile := lyricsDir + "/" + t.GetFileName(ctx, track) + ".txt"
+	if synced {
+		lyricsFile = strings.TrimSuffix(file, ".mp3") + ".lrc"
+	}
+	if _, err := writeFile(lyricsFile, strings.NewReader(strings.TrimRight(lyrics, "\n")+"\n")); err != nil {
+		return err
+	}
+
+	if t.client.lyrics == nil || !t.client.lyrics.Embed {
+		return nil
+	}
+	text := lyrics
+	if synced {
+		text = lrcToText(lyrics)
+	}
+	return embedLyrics(file, text, t.client.lyrics.Language)
+}
+
+// getStorage requests file from the storage by uri and passes its body
+// to read that returns number of read bytes
+func (t *TracksService) getStorage(
+	ctx context.Context,
+	uri string,
+	read func(r io.Reader) (int64, error),
+) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, finish := t.client.telemetry.startRequest(ctx, req)
+	var resp *http.Response
+	defer func() { finish(resp, err) }()
+
+	// Storage host doesn't need authorization, so request is sent directly
+	// through the HTTP client to get retries without leaking the token
+	resp, err = t.client.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{Response: resp, StatusCode: resp.StatusCode}
+	}
+
+	n, err := read(resp.Body)
+	t.client.telemetry.addDownloaded(ctx, n)
+	return err
+}
+
+// lyricsSign returns signature of lyrics request of track by id at timestamp
+func lyricsSign(id, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(lyricsSignKey))
+	mac.Write([]byte(id + timestamp))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// lrcToText returns lyrics in LRC format as plain text without time
+// and metadata tags
+func lrcToText(lrc string) string {
+	var lines []string
+	for _, line := range strings.Split(lrc, "\n") {
+		line = strings.TrimSpace(line)
+		if lrcMetaTag.MatchString(line) {
+			continue
+		}
+		lines = append(lines, strings.TrimSpace(lrcTimeTag.ReplaceAllString(line, "")))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,46 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracksService_DownloadLyrics(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tracks/1/lyrics", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "LRC", query.Get("format"))
+		ts, err := strconv.ParseInt(query.Get("timeStamp"), 10, 64)
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Now().Unix(), ts, 5)
+		assert.Equal(t, lyricsSign("1", query.Get("timeStamp")), query.Get("sign"))
+		fmt.Fprintf(w, `{"result":{"downloadUrl":"%s/storage/1.lrc","lyricId":5}}`, server.URL)
+	})
+	mux.HandleFunc("/storage/1.lrc", func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprint(w, "[00:01.00] Paralysed\n")
+	})
+
+	lyrics, err := client.Tracks().DownloadLyrics(context.Background(), "1:10", LyricsLRC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[00:01.00] Paralysed\n", lyrics)
+}
+
+func TestLyricsSign(t *testing.T) {
+	assert.Equal(t, "6Dzv8y14nw5mpoFC0HC0PQLiEUg1FSltm71hhVEn6cA=", lyricsSign("1", "1700000000"))
+}
+
+func TestLrcToText(t *testing.T) {
+	lrc := "[ar: Muse]\n[ti: Uprising]\n[00:01.00] Paralysed\n[00:03.50]They will not\n[00:05.00]\n"
+
+	assert.Equal(t, "Paralysed\nThey will not", lrcToText(lrc))
+}
@@ -2,13 +2,16 @@ package yamusic
 
 import (
 	"awesome/config"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
-	"os"
 	"slices"
 	"strconv"
 	"strings"
@@ -161,12 +164,30 @@ type (
 		} `json:"result"`
 	}
 
+	// PlaylistsRecommendationsResp describes get playlist recommendations
+	// response
+	PlaylistsRecommendationsResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			BatchID string  `json:"batchId"`
+			Tracks  []Track `json:"tracks"`
+		} `json:"result"`
+	}
+
 	// PlaylistsRenameResp describes method rename playlist response
 	PlaylistsRenameResp struct {
 		InvocationInfo InvocationInfo  `json:"invocationInfo"`
 		Error          Error           `json:"error"`
 		Result         PlaylistsResult `json:"result"`
 	}
+	// PlaylistsEditResp describes methods changing description,
+	// visibility or cover of playlist response
+	PlaylistsEditResp struct {
+		InvocationInfo InvocationInfo  `json:"invocationInfo"`
+		Error          Error           `json:"error"`
+		Result         PlaylistsResult `json:"result"`
+	}
 	// PlaylistsCreateResp describes method create playlist response
 	PlaylistsCreateResp struct {
 		InvocationInfo InvocationInfo  `json:"invocationInfo"`
@@ -361,6 +382,23 @@ func (s *PlaylistsService) GetByKinds(
 	return playlists, resp, err
 }
 
+// GetRecommendations returns tracks recommended for playlist of current
+// user by kind
+func (s *PlaylistsService) GetRecommendations(
+	ctx context.Context,
+	kind int,
+) (*PlaylistsRecommendationsResp, *http.Response, error) {
+	uri := fmt.Sprintf("users/%v/playlists/%v/recommendations", s.client.userID, kind)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recommendations := new(PlaylistsRecommendationsResp)
+	resp, err := s.client.Do(ctx, req, recommendations)
+	return recommendations, resp, err
+}
+
 // Rename renames playlist of current user
 func (s *PlaylistsService) Rename(
 	ctx context.Context,
@@ -384,6 +422,113 @@ func (s *PlaylistsService) Rename(
 	return renamedPlaylist, resp, err
 }
 
+// SetDescription changes description of playlist of current user
+func (s *PlaylistsService) SetDescription(
+	ctx context.Context,
+	kind int,
+	description string,
+) (*PlaylistsEditResp, *http.Response, error) {
+	form := url.Values{}
+	form.Set("value", description)
+	return s.edit(ctx, kind, "description", form)
+}
+
+// SetVisibility makes playlist of current user public or private
+func (s *PlaylistsService) SetVisibility(
+	ctx context.Context,
+	kind int,
+	isPublic bool,
+) (*PlaylistsEditResp, *http.Response, error) {
+	form := url.Values{}
+	if isPublic {
+		form.Set("value", "public")
+	} else {
+		form.Set("value", "private")
+	}
+	return s.edit(ctx, kind, "visibility", form)
+}
+
+// UploadCover sets image read from r as custom cover of playlist
+// of current user. Image should be in JPEG or PNG format.
+func (s *PlaylistsService) UploadCover(
+	ctx context.Context,
+	kind int,
+	r io.Reader,
+) (*PlaylistsEditResp, *http.Response, error) {
+	image, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="image"; filename="cover"`)
+	header.Set("Content-Type", http.DetectContentType(image))
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := part.Write(image); err != nil {
+		return nil, nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	uri := fmt.Sprintf("users/%v/playlists/%v/cover/upload", s.client.userID, kind)
+	req, err := s.client.NewRequest(http.MethodPost, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Body is set by hand since NewRequest encodes only forms and JSON.
+	// GetBody lets the request be repeated after token renewal.
+	data := body.Bytes()
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	uploaded := new(PlaylistsEditResp)
+	resp, err := s.client.Do(ctx, req, uploaded)
+	return uploaded, resp, err
+}
+
+// ClearCover removes custom cover of playlist of current user,
+// so cover is made of its tracks again
+func (s *PlaylistsService) ClearCover(
+	ctx context.Context,
+	kind int,
+) (*PlaylistsEditResp, *http.Response, error) {
+	return s.edit(ctx, kind, "cover/clear", nil)
+}
+
+// edit posts form to method of playlist of current user
+func (s *PlaylistsService) edit(
+	ctx context.Context,
+	kind int,
+	method string,
+	form url.Values,
+) (*PlaylistsEditResp, *http.Response, error) {
+	uri := fmt.Sprintf("users/%v/playlists/%v/%v", s.client.userID, kind, method)
+
+	var body interface{}
+	if form != nil {
+		body = form
+	}
+	req, err := s.client.NewRequest(http.MethodPost, uri, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	edited := new(PlaylistsEditResp)
+	resp, err := s.client.Do(withRetrySafe(ctx), req, edited)
+	return edited, resp, err
+}
+
 // Create creates playlist for current user
 func (s *PlaylistsService) Create(
 	ctx context.Context,
@@ -489,7 +634,7 @@ func (s *PlaylistsService) AddTracks(
 	}
 
 	addTracksResp := new(PlaylistsAddTracksResp)
-	resp, err := s.client.Do(ctx, req, addTracksResp)
+	resp, err := s.client.Do(withRetryUnapplied(ctx), req, addTracksResp)
 	return addTracksResp, resp, err
 }
 
@@ -550,38 +695,34 @@ func (s *PlaylistsService) RemoveTracks(
 		return nil, nil, err
 	}
 
-	addTracksResp := new(PlaylistsRemoveTracksResp)
-	resp, err := s.client.Do(ctx, req, addTracksResp)
-	return addTracksResp, resp, err
+	removeTracksResp := new(PlaylistsRemoveTracksResp)
+	resp, err := s.client.Do(withRetryUnapplied(ctx), req, removeTracksResp)
+	return removeTracksResp, resp, err
 }
 
-func (s *PlaylistsService) DownloadOne(ctx context.Context, kind int) {
-	resp, _, _ := s.Get(ctx, 0, kind)
+// DownloadOne downloads tracks of playlist by kind into a folder named
+// by playlist's title under the output directory from config
+func (s *PlaylistsService) DownloadOne(ctx context.Context, kind int) (*DownloadResult, error) {
+	resp, _, err := s.Get(ctx, 0, kind)
+	if err != nil {
+		return nil, err
+	}
 	playlist := resp.Result
 
 	/// Get already loaded tracks (already on yandex disk and file system)
 	if len(playlist.Tracks) < 1 {
-		logInfo.Println("No tracks in playlist: ", playlist.PlaylistsResult.Title)
-		return
+		s.client.logger.Info("no tracks in playlist",
+			"playlist_kind", kind, "title", playlist.PlaylistsResult.Title)
+		return new(DownloadResult), nil
 	}
 
-	logInfo.Printf("Count tracks in playlist %s: %d", playlist.PlaylistsResult.Title, len(playlist.Tracks))
-	logInfo.Printf("Playlist to download: %s", playlist.PlaylistsResult.Title)
+	s.client.logger.Info("playlist to download",
+		"playlist_kind", kind,
+		"title", playlist.PlaylistsResult.Title,
+		"tracks", len(playlist.Tracks),
+	)
 
 	playlist_folder := s.client.config.Output + "/" + playlist.PlaylistsResult.Title
-	// Create dirs if they are not exist
-	if _, err := os.Stat(playlist_folder + "/tracks"); os.IsNotExist(err) {
-		err := os.MkdirAll(playlist_folder+"/tracks", os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
-	if _, err := os.Stat(playlist_folder + "/lyrics"); os.IsNotExist(err) {
-		err := os.MkdirAll(playlist_folder+"/lyrics", os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
 
 	/// Get list directory
 	var tracks []Track
@@ -589,91 +730,284 @@ func (s *PlaylistsService) DownloadOne(ctx context.Context, kind int) {
 		tracks = append(tracks, track.Track)
 	}
 
-	s.client.tracks.DownloadAll(ctx, tracks, playlist_folder)
+	return s.client.tracks.DownloadAll(ctx, tracks, playlist_folder)
 }
 
-func (s *PlaylistsService) DownloadAll(ctx context.Context, kinds []int) {
-
+// DownloadAll downloads playlists by kinds or all playlists of the user if
+// kinds are empty. Failed playlists and tracks don't stop the download,
+// their errors are joined into the returned error.
+func (s *PlaylistsService) DownloadAll(ctx context.Context, kinds []int) (*DownloadResult, error) {
 	if len(kinds) < 1 {
-		result, _, _ := s.client.Playlists().List(context.Background(), 0)
+		result, _, err := s.List(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
 		playlists := result.Result
 		for _, playlist := range playlists {
 			kinds = append(kinds, playlist.Kind)
 		}
 	}
+
+	result := new(DownloadResult)
+	var errs []error
 	for _, kind := range kinds {
-		s.DownloadOne(ctx, kind)
+		if err := ctx.Err(); err != nil {
+			return result, errors.Join(append(errs, err)...)
+		}
+
+		downloaded, err := s.DownloadOne(ctx, kind)
+		result.add(downloaded)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("playlist %d: %w", kind, err))
+		}
 	}
+
+	return result, errors.Join(errs...)
 }
 
-// AddTracks adds tracks to playlist
-func (s *PlaylistsService) DistributeTracksByPlaylists() {
-	var id int
-	var add_tracks []PlaylistsTrack
-	playlists_map, _ := config.CreatePlaylistsMap()
-	tracks_out_playlist := s.client.GetTracksWithoutPlaylist()
+// DistributeTracksByPlaylists adds liked tracks that are in no playlist to
+// playlists from playlists map by their first artist. It returns number of
+// added tracks by playlist kind.
+func (s *PlaylistsService) DistributeTracksByPlaylists(ctx context.Context) (map[int]int, error) {
+	playlists_map, err := config.CreatePlaylistsMap()
+	if err != nil {
+		return nil, err
+	}
+	tracks_out_playlist, err := s.client.GetTracksWithoutPlaylist(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	added := map[int]int{}
+	var errs []error
 	for _, iter := range playlists_map.Playlists {
-		add_tracks = nil
+		if err := ctx.Err(); err != nil {
+			return added, errors.Join(append(errs, err)...)
+		}
+
+		var add_tracks []PlaylistsTrack
 		for _, track := range tracks_out_playlist {
-			if slices.Contains(iter.Authors, track.Artists[0].Name) {
-				id, _ = strconv.Atoi(track.ID)
-				add_tracks = append(add_tracks, PlaylistsTrack{
-					ID: id, AlbumID: track.Albums[0].ID,
-				})
+			if len(track.Artists) == 0 || !slices.Contains(iter.Authors, track.Artists[0].Name) {
+				continue
+			}
+			playlist_track, err := newPlaylistsTrack(track)
+			if err != nil {
+				errs = append(errs, err)
+				continue
 			}
+			add_tracks = append(add_tracks, playlist_track)
 		}
-		res1, _, _ := s.client.Playlists().Get(context.Background(), 0, iter.Kind) // for getting revision
-		log.Printf("%d\n", len(add_tracks))
-		s.client.Playlists().AddTracks(context.Background(), iter.Kind, res1.Result.Revision, add_tracks, nil)
+		if len(add_tracks) == 0 {
+			continue
+		}
+
+		s.client.logger.Info("add tracks to playlist", "playlist_kind", iter.Kind, "tracks", len(add_tracks))
+		if err := s.addTracks(ctx, iter.Kind, add_tracks); err != nil {
+			errs = append(errs, fmt.Errorf("playlist %d: %w", iter.Kind, err))
+			continue
+		}
+		added[iter.Kind] = len(add_tracks)
 	}
+
+	return added, errors.Join(errs...)
 }
 
-// Add tracks to playlist
-func (s *PlaylistsService) AddTracksToPlaylist() {
+// Extend adds up to n recommended tracks by artists of playlist of current
+// user by kind to its top. Tracks recommended for playlist go first,
+// then tracks similar to its tracks. It returns added tracks. Failing to get
+// similar tracks of a track is an error only if it failed for every track.
+func (s *PlaylistsService) Extend(ctx context.Context, kind int, n int) ([]Track, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	playlist, _, err := s.Get(BypassCache(ctx), 0, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	artists := map[int]bool{}
+	seen := map[string]bool{}
+	for _, track := range playlist.Result.Tracks {
+		seen[track.Track.ID] = true
+		for _, artist := range track.Track.Artists {
+			artists[artist.ID] = true
+		}
+	}
+
+	var found []Track
+	var addTracks []PlaylistsTrack
+	pick := func(candidates []Track) {
+		for _, track := range candidates {
+			if len(found) == n {
+				return
+			}
+			if seen[track.ID] || !slices.ContainsFunc(track.Artists, func(a Artist) bool { return artists[a.ID] }) {
+				continue
+			}
+			seen[track.ID] = true
+			playlistTrack, err := newPlaylistsTrack(track)
+			if err != nil {
+				continue
+			}
+			found = append(found, track)
+			addTracks = append(addTracks, playlistTrack)
+		}
+	}
+
+	recommendations, _, err := s.GetRecommendations(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	pick(recommendations.Result.Tracks)
+
+	// One bad track mustn't lose tracks found by the others
+	var errs []error
+	seeded := false
+	for _, track := range playlist.Result.Tracks {
+		if len(found) == n {
+			break
+		}
+		similar, _, err := s.client.tracks.GetSimilar(ctx, track.Track.ID)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			s.client.logger.Warn("cannot get similar tracks", "track_id", track.Track.ID, "error", err)
+			errs = append(errs, fmt.Errorf("similar tracks of %s: %w", track.Track.ID, err))
+			continue
+		}
+		seeded = true
+		pick(similar.Result.SimilarTracks)
+	}
+	if len(found) == 0 {
+		if !seeded && len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return nil, nil
+	}
+
+	s.client.logger.Info("extend playlist", "playlist_kind", kind, "tracks", len(found))
+	_, _, err = s.AddTracks(ctx, kind, playlist.Result.Revision, addTracks, nil)
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// AddTracksToPlaylist adds test tracks to testing playlist
+func (s *PlaylistsService) AddTracksToPlaylist(ctx context.Context) error {
 	// kind 1069 - testing playlist
-	track_ids := []int{20345844, 21825973}
-	playlists_track := []PlaylistsTrack{}
-	for _, track_id := range track_ids {
-		track, _, _ := s.client.Tracks().GetOne(context.Background(), track_id)
-		id, _ := strconv.Atoi(track.Result[0].ID)
-		playlists_track = append(playlists_track, PlaylistsTrack{
-			ID: id, AlbumID: track.Result[0].Albums[0].ID,
-		})
-	}
-	res1, _, _ := s.client.Playlists().Get(context.Background(), 0, 1069) // for getting revision
-	s.client.Playlists().AddTracks(context.Background(), 1069, res1.Result.Revision, playlists_track, nil)
+	playlists_track, err := s.getPlaylistsTracks(ctx, []int{20345844, 21825973})
+	if err != nil {
+		return err
+	}
+	return s.addTracks(ctx, 1069, playlists_track)
 }
 
-// Delete track from playlists
-func (s *PlaylistsService) DeleteTracksFromPlaylists() {
-	var delete_tracks []PlaylistsTrack
-	var id int
-	playlists_map, _ := config.CreatePlaylistsMap()
-	for _, playlist := range playlists_map.Playlists {
-		delete_tracks = nil
-		res1, _, _ := s.client.Playlists().Get(context.Background(), 0, playlist.Kind)
-		for _, track := range res1.Result.Tracks {
-			id, _ = strconv.Atoi(track.Track.ID)
-			// log.Printf("Title: %s, albums len: %d", track.Track.Title, len(track.Track.Albums))
-			delete_tracks = append(delete_tracks, PlaylistsTrack{
-				ID: id, AlbumID: track.Track.Albums[0].ID,
-			})
+// DeleteTracksFromPlaylists removes all tracks from playlists of playlists map.
+// Playlist with a track that can't be removed is left untouched.
+func (s *PlaylistsService) DeleteTracksFromPlaylists(ctx context.Context) error {
+	playlistsMap, err := config.CreatePlaylistsMap()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, playlist := range playlistsMap.Playlists {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
 		}
-		s.client.Playlists().RemoveTracks(context.Background(), playlist.Kind, res1.Result.Revision, delete_tracks, nil)
+
+		current, _, err := s.Get(BypassCache(ctx), 0, playlist.Kind)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("playlist %d: %w", playlist.Kind, err))
+			continue
+		}
+
+		// Tracks are removed as one range from the top, so a skipped track
+		// would shift positions of the following ones
+		var deleteTracks []PlaylistsTrack
+		for _, track := range current.Result.Tracks {
+			playlistTrack, err := newPlaylistsTrack(track.Track)
+			if err != nil {
+				deleteTracks = nil
+				errs = append(errs, fmt.Errorf("playlist %d: %w", playlist.Kind, err))
+				break
+			}
+			deleteTracks = append(deleteTracks, playlistTrack)
+		}
+		if len(deleteTracks) == 0 {
+			continue
+		}
+
+		_, _, err = s.RemoveTracks(ctx, playlist.Kind, current.Result.Revision, deleteTracks, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("playlist %d: %w", playlist.Kind, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// DeleteTracksFromPlaylist removes test tracks from testing playlist
+func (s *PlaylistsService) DeleteTracksFromPlaylist(ctx context.Context) error {
+	playlists_track, err := s.getPlaylistsTracks(ctx, []int{20345844, 21825973})
+	if err != nil {
+		return err
+	}
+
+	res1, _, err := s.Get(BypassCache(ctx), 0, 1069) // for getting revision
+	if err != nil {
+		return err
 	}
+	_, _, err = s.RemoveTracks(ctx, 1069, res1.Result.Revision, playlists_track, nil)
+	return err
 }
 
-// Delete tracks from playlist
-func (s *PlaylistsService) DeleteTracksFromPlaylist() {
-	track_ids := []int{20345844, 21825973}
+// addTracks adds tracks to the top of playlist with its current revision
+func (s *PlaylistsService) addTracks(ctx context.Context, kind int, tracks []PlaylistsTrack) error {
+	res1, _, err := s.Get(BypassCache(ctx), 0, kind) // for getting revision
+	if err != nil {
+		return err
+	}
+	_, _, err = s.AddTracks(ctx, kind, res1.Result.Revision, tracks, nil)
+	return err
+}
+
+// getPlaylistsTracks returns tracks by ids for adding to and removing
+// from playlists
+func (s *PlaylistsService) getPlaylistsTracks(ctx context.Context, track_ids []int) ([]PlaylistsTrack, error) {
 	playlists_track := []PlaylistsTrack{}
 	for _, track_id := range track_ids {
-		track, _, _ := s.client.Tracks().GetOne(context.Background(), track_id)
-		id, _ := strconv.Atoi(track.Result[0].ID)
-		playlists_track = append(playlists_track, PlaylistsTrack{
-			ID: id, AlbumID: track.Result[0].Albums[0].ID,
-		})
-	}
-	res1, _, _ := s.client.Playlists().Get(context.Background(), 0, 1069) // for getting revision
-	s.client.Playlists().RemoveTracks(context.Background(), 1069, res1.Result.Revision, playlists_track, nil)
+		track, _, err := s.client.Tracks().GetOne(ctx, track_id)
+		if err != nil {
+			return nil, err
+		}
+		if len(track.Result) == 0 {
+			return nil, fmt.Errorf("track %d: %w", track_id, ErrNotFound)
+		}
+		playlist_track, err := newPlaylistsTrack(track.Result[0])
+		if err != nil {
+			return nil, err
+		}
+		playlists_track = append(playlists_track, playlist_track)
+	}
+	return playlists_track, nil
+}
+
+// newPlaylistsTrack returns track with ids needed to add it to playlist
+// or remove from it
+func newPlaylistsTrack(track Track) (PlaylistsTrack, error) {
+	id, err := strconv.Atoi(track.ID)
+	if err != nil {
+		return PlaylistsTrack{}, fmt.Errorf("track %q: %w", track.ID, err)
+	}
+	if len(track.Albums) == 0 {
+		return PlaylistsTrack{}, fmt.Errorf("track %d has no album", id)
+	}
+	return PlaylistsTrack{ID: id, AlbumID: track.Albums[0].ID}, nil
 }
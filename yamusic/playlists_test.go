@@ -1,11 +1,16 @@
 package yamusic
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -216,6 +221,143 @@ func TestPlaylistsService_Delete(t *testing.T) {
 	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
 }
 
+func TestPlaylistsService_SetDescription(t *testing.T) {
+	setup()
+	defer teardown()
+
+	want := &PlaylistsEditResp{}
+	want.InvocationInfo.ReqID = "Playlists.SetDescription"
+	want.Result.Description = "Songs for the road"
+
+	kind := 1004
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/description", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			err := r.ParseForm()
+			assert.NoError(t, err)
+			assert.Equal(t, "Songs for the road", r.FormValue("value"))
+			assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+
+			b, err := json.Marshal(want)
+			assert.NoError(t, err)
+			fmt.Fprint(w, string(b))
+		},
+	)
+
+	result, _, err := client.Playlists().SetDescription(context.Background(), kind, "Songs for the road")
+
+	assert.NoError(t, err)
+	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+	assert.Equal(t, "Songs for the road", result.Result.Description)
+}
+
+func TestPlaylistsService_SetVisibility(t *testing.T) {
+	setup()
+	defer teardown()
+
+	want := &PlaylistsEditResp{}
+	want.InvocationInfo.ReqID = "Playlists.SetVisibility"
+	want.Result.Visibility = "private"
+
+	kind := 1004
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/visibility", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			err := r.ParseForm()
+			assert.NoError(t, err)
+			assert.Equal(t, "private", r.FormValue("value"))
+			assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+
+			b, err := json.Marshal(want)
+			assert.NoError(t, err)
+			fmt.Fprint(w, string(b))
+		},
+	)
+
+	result, _, err := client.Playlists().SetVisibility(context.Background(), kind, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+	assert.Equal(t, "private", result.Result.Visibility)
+}
+
+func TestPlaylistsService_UploadCover(t *testing.T) {
+	setup()
+	defer teardown()
+
+	want := &PlaylistsEditResp{}
+	want.InvocationInfo.ReqID = "Playlists.UploadCover"
+	want.Result.Cover.Custom = true
+
+	kind := 1004
+	image := []byte("\xff\xd8\xff\xe0jpeg")
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/cover/upload", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+
+			file, header, err := r.FormFile("image")
+			if assert.NoError(t, err) {
+				defer file.Close()
+				assert.Equal(t, "image/jpeg", header.Header.Get("Content-Type"))
+				b, err := io.ReadAll(file)
+				assert.NoError(t, err)
+				assert.Equal(t, image, b)
+			}
+
+			b, err := json.Marshal(want)
+			assert.NoError(t, err)
+			fmt.Fprint(w, string(b))
+		},
+	)
+
+	result, _, err := client.Playlists().UploadCover(context.Background(), kind, bytes.NewReader(image))
+
+	assert.NoError(t, err)
+	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+	assert.True(t, result.Result.Cover.Custom)
+}
+
+func TestPlaylistsService_ClearCover(t *testing.T) {
+	setup()
+	defer teardown()
+
+	want := &PlaylistsEditResp{}
+	want.InvocationInfo.ReqID = "Playlists.ClearCover"
+
+	kind := 1004
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/cover/clear", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			err := r.ParseForm()
+			assert.NoError(t, err)
+			assert.Empty(t, r.PostForm)
+			assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+
+			b, err := json.Marshal(want)
+			assert.NoError(t, err)
+			fmt.Fprint(w, string(b))
+		},
+	)
+
+	result, _, err := client.Playlists().ClearCover(context.Background(), kind)
+
+	assert.NoError(t, err)
+	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+	assert.False(t, result.Result.Cover.Custom)
+}
+
 func TestPlaylistsService_AddTracks(t *testing.T) {
 	setup()
 	defer teardown()
@@ -269,6 +411,145 @@ func TestPlaylistsService_AddTracks(t *testing.T) {
 	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
 }
 
+func TestPlaylistsService_Extend(t *testing.T) {
+	setup()
+	defer teardown()
+
+	kind := 1004
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"kind":1004,"revision":7,"tracks":[
+			{"id":1,"track":{"id":"1","artists":[{"id":10}],"albums":[{"id":100}]}}
+		]}}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v/recommendations", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"batchId":"b1","tracks":[
+			{"id":"2","artists":[{"id":20}],"albums":[{"id":200}]},
+			{"id":"3","artists":[{"id":10}],"albums":[{"id":300}]}
+		]}}`)
+	})
+	mux.HandleFunc("/tracks/1/similar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"similarTracks":[
+			{"id":"3","artists":[{"id":10}],"albums":[{"id":300}]},
+			{"id":"4","artists":[{"id":30},{"id":10}],"albums":[{"id":400}]},
+			{"id":"5","artists":[{"id":10}],"albums":[{"id":500}]}
+		]}}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v/change-relative", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "7", r.FormValue("revision"))
+		assert.Equal(t, `[{"op":"insert","at":0,"tracks":[{"id":3,"albumId":300},{"id":4,"albumId":400}]}]`, r.FormValue("diff"))
+		fmt.Fprint(w, `{"result":{"kind":1004,"revision":8}}`)
+	})
+
+	added, err := client.Playlists().Extend(context.Background(), kind, 2)
+
+	assert.NoError(t, err)
+	if assert.Len(t, added, 2) {
+		assert.Equal(t, "3", added[0].ID)
+		assert.Equal(t, "4", added[1].ID)
+	}
+}
+
+func TestPlaylistsService_ExtendSkipsFailedSeeds(t *testing.T) {
+	setup()
+	defer teardown()
+
+	kind := 1004
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"kind":1004,"revision":7,"tracks":[
+			{"id":1,"track":{"id":"1","artists":[{"id":10}],"albums":[{"id":100}]}},
+			{"id":2,"track":{"id":"2","artists":[{"id":10}],"albums":[{"id":200}]}},
+			{"id":3,"track":{"id":"3","artists":[{"id":10}],"albums":[{"id":300}]}}
+		]}}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v/recommendations", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"tracks":[]}}`)
+	})
+	mux.HandleFunc("/tracks/1/similar", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/tracks/2/similar", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/tracks/3/similar", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"similarTracks":[{"id":"4","artists":[{"id":10}],"albums":[{"id":400}]}]}}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v/change-relative", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, `[{"op":"insert","at":0,"tracks":[{"id":4,"albumId":400}]}]`, r.FormValue("diff"))
+		fmt.Fprint(w, `{"result":{"kind":1004,"revision":8}}`)
+	})
+
+	added, err := client.Playlists().Extend(context.Background(), kind, 2)
+
+	assert.NoError(t, err)
+	if assert.Len(t, added, 1) {
+		assert.Equal(t, "4", added[0].ID)
+	}
+}
+
+func TestPlaylistsService_ExtendAllSeedsFailed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	kind := 1004
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"kind":1004,"revision":7,"tracks":[
+			{"id":1,"track":{"id":"1","artists":[{"id":10}],"albums":[{"id":100}]}}
+		]}}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/%v/recommendations", userID, kind), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"tracks":[]}}`)
+	})
+	mux.HandleFunc("/tracks/1/similar", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	added, err := client.Playlists().Extend(context.Background(), kind, 2)
+
+	assert.Error(t, err)
+	assert.Empty(t, added)
+}
+
+func TestPlaylistsService_DeleteTracksFromPlaylists(t *testing.T) {
+	setup()
+	defer teardown()
+
+	dir := t.TempDir()
+	playlistsMap := "playlists:\n  - title: Broken\n    kind: 1\n  - title: Rock\n    kind: 2\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "playlists_map.yaml"), []byte(playlistsMap), 0o644))
+	t.Chdir(dir)
+
+	// The second track of playlist 1 can't be removed, so neither is the first
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/1", userID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"kind":1,"revision":3,"tracks":[
+			{"id":1,"track":{"id":"1","albums":[{"id":10}]}},
+			{"id":2,"track":{"id":"2"}}
+		]}}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/1/change-relative", userID), func(w http.ResponseWriter, r *http.Request) {
+		t.Error("tracks of playlist with unknown album are removed")
+	})
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/2", userID), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"kind":2,"revision":5,"tracks":[
+			{"id":3,"track":{"id":"3","albums":[{"id":30}]}}
+		]}}`)
+	})
+	removed := false
+	mux.HandleFunc(fmt.Sprintf("/users/%v/playlists/2/change-relative", userID), func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "5", r.FormValue("revision"))
+		assert.Equal(t, `[{"op":"delete","from":0,"to":1,"tracks":[{"id":3,"albumId":30}]}]`, r.FormValue("diff"))
+		removed = true
+		fmt.Fprint(w, `{"result":{"kind":2,"revision":6}}`)
+	})
+
+	err := client.Playlists().DeleteTracksFromPlaylists(context.Background())
+
+	assert.ErrorContains(t, err, "playlist 1")
+	assert.True(t, removed)
+}
+
 func TestPlaylistsService_RemoveTracks(t *testing.T) {
 	setup()
 	defer teardown()
@@ -321,3 +602,87 @@ func TestPlaylistsService_RemoveTracks(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
 }
+
+func TestPlaylistsService_DownloadAll(t *testing.T) {
+	setup(func(c *Client) { c.config.Output = t.TempDir() })
+	defer teardown()
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/list", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"result":[{"uid":%v,"kind":1},{"uid":%v,"kind":2}]}`, userID, userID)
+		},
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/1", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":1,"title":"Empty","tracks":[]}}`, userID)
+		},
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/2", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+	)
+
+	result, err := client.Playlists().DownloadAll(context.Background(), nil)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "playlist 2")
+	assert.Equal(t, 0, result.Downloaded)
+}
+
+func TestClient_GetTracksWithoutPlaylist(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/likes/tracks", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"result":{"library":{"tracks":[{"id":"1"},{"id":"2"}]}}}`)
+		},
+	)
+	mux.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "1,2", r.FormValue("track-ids"))
+		fmt.Fprint(w, `{"result":[{"id":"1"},{"id":"2"}]}`)
+	})
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/list", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"result":[{"uid":%v,"kind":1}]}`, userID)
+		},
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/1", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"result":{"uid":%v,"kind":1,"tracks":[{"track":{"id":"2"}}]}}`, userID)
+		},
+	)
+
+	tracks, err := client.GetTracksWithoutPlaylist(context.Background())
+
+	assert.NoError(t, err)
+	if assert.Len(t, tracks, 1) {
+		assert.Equal(t, "1", tracks[0].ID)
+	}
+}
+
+func TestClient_PrintPlaylists(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/list", userID),
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		},
+	)
+
+	buf := new(strings.Builder)
+	err := client.PrintPlaylists(context.Background(), buf)
+
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,332 @@
+package yamusic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultPlaysBatchSize is the max number of plays in one report
+// if no batch size is set
+const defaultPlaysBatchSize = 100
+
+// ErrNoUserID is returned by methods keeping plays in the outbox if user id
+// isn't known, since outbox is kept per user
+var ErrNoUserID = ClientError("user id is unknown")
+
+// outboxLocks are locks of outbox files by path. Clients of the same user,
+// e.g. made by WithToken, share the outbox, so the lock isn't per service.
+var outboxLocks sync.Map
+
+type (
+	// PlaysService is a service to report tracks played by the user, so they
+	// are taken into account in history and recommendations
+	PlaysService struct {
+		client *Client
+	}
+	// Play is a play of track
+	Play struct {
+		TrackID string `json:"trackId"`
+		AlbumID string `json:"albumId,omitempty"`
+		// PlaylistID is id of playlist track is played from as uid:kind
+		PlaylistID string `json:"playlistId,omitempty"`
+		// From describes where track is played from, e.g. "playlist"
+		From string `json:"from"`
+		// PlayID identifies play. Record fills in random one if it's empty.
+		PlayID               string    `json:"playId"`
+		Timestamp            time.Time `json:"timestamp"`
+		StartPositionSeconds float64   `json:"startPositionSeconds"`
+		EndPositionSeconds   float64   `json:"endPositionSeconds"`
+		TotalPlayedSeconds   float64   `json:"totalPlayedSeconds"`
+		TrackLengthSeconds   float64   `json:"trackLengthSeconds"`
+		FromCache            bool      `json:"fromCache"`
+	}
+	// PlaysOptions configures reporting of plays
+	PlaysOptions struct {
+		// Dir is directory of outbox keeping plays that aren't reported yet.
+		// Default is .plays directory under configured output directory.
+		Dir string
+		// BatchSize is the max number of plays in one report. Default is 100.
+		BatchSize int
+	}
+	// PlaysResp describes report plays response
+	PlaysResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         string         `json:"result"`
+	}
+)
+
+// Plays sets options of reporting plays.
+// If opts is nil, default options are used.
+func Plays(opts *PlaysOptions) func(*Client) {
+	return func(c *Client) {
+		if opts == nil {
+			opts = &PlaysOptions{}
+		}
+		c.playReports = opts
+	}
+}
+
+// PlaylistPlayID returns id of playlist for Play.PlaylistID
+func PlaylistPlayID(uid, kind int) string {
+	return fmt.Sprintf("%d:%d", uid, kind)
+}
+
+// Report sends plays to the API at once. Use Record to keep plays
+// that fail to be reported.
+func (s *PlaysService) Report(ctx context.Context, plays ...Play) (*PlaysResp, *http.Response, error) {
+	queryParams := url.Values{}
+	queryParams.Set("client-now", time.Now().Format(time.RFC3339Nano))
+
+	body := struct {
+		Plays []Play `json:"plays"`
+	}{plays}
+
+	req, err := s.client.NewRequest(http.MethodPost, "plays?"+queryParams.Encode(), body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	reported := new(PlaysResp)
+	resp, err := s.client.Do(ctx, req, reported)
+	return reported, resp, err
+}
+
+// Record saves plays into the outbox and reports all plays of the outbox.
+// Empty PlayID, Timestamp and From of plays are filled in. Plays that
+// fail to be reported stay in the outbox and are reported by the next
+// Record or Flush, even after restart.
+func (s *PlaysService) Record(ctx context.Context, plays ...Play) error {
+	for i := range plays {
+		if plays[i].PlayID == "" {
+			plays[i].PlayID = newPlayID()
+		}
+		if plays[i].Timestamp.IsZero() {
+			plays[i].Timestamp = time.Now()
+		}
+		if plays[i].From == "" {
+			plays[i].From = "yamusic"
+		}
+	}
+
+	if err := s.appendOutbox(plays); err != nil {
+		return err
+	}
+	_, err := s.Flush(ctx)
+	return err
+}
+
+// Flush reports plays of the outbox in batches and returns number
+// of reported plays. Plays rejected by the API as invalid are dropped,
+// otherwise the outbox keeps plays not reported.
+func (s *PlaysService) Flush(ctx context.Context) (int, error) {
+	path, err := s.outbox()
+	if err != nil {
+		return 0, err
+	}
+	defer lockOutbox(path)()
+
+	plays, err := s.readOutbox(path)
+	if err != nil {
+		return 0, err
+	}
+
+	batchSize := defaultPlaysBatchSize
+	if s.client.playReports != nil && s.client.playReports.BatchSize > 0 {
+		batchSize = s.client.playReports.BatchSize
+	}
+
+	reported := 0
+	for len(plays) > 0 {
+		batch := plays[:min(batchSize, len(plays))]
+		left, n, reportErr := s.reportValid(ctx, batch)
+		reported += n
+		plays = plays[len(batch):]
+		if reportErr != nil {
+			plays = slices.Concat(left, plays)
+			err = reportErr
+			break
+		}
+	}
+
+	if writeErr := writeOutbox(path, plays); writeErr != nil {
+		return reported, writeErr
+	}
+	return reported, err
+}
+
+// Pending returns plays of the outbox that aren't reported yet
+func (s *PlaysService) Pending() ([]Play, error) {
+	path, err := s.outbox()
+	if err != nil {
+		return nil, err
+	}
+	defer lockOutbox(path)()
+
+	return s.readOutbox(path)
+}
+
+// reportValid reports batch of plays. Batch rejected as invalid is split
+// in halves until the invalid plays are found and dropped. It returns
+// plays left unreported because of error and number of reported plays.
+func (s *PlaysService) reportValid(ctx context.Context, batch []Play) ([]Play, int, error) {
+	_, _, err := s.Report(ctx, batch...)
+
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return nil, len(batch), nil
+	case !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest:
+		return batch, 0, err
+	case len(batch) == 1:
+		s.client.logger.Warn("play rejected, dropped", "play_id", batch[0].PlayID, "track_id", batch[0].TrackID, "error", err)
+		return nil, 0, nil
+	}
+
+	half := len(batch) / 2
+	left, reported, err := s.reportValid(ctx, batch[:half])
+	if err != nil {
+		return slices.Concat(left, batch[half:]), reported, err
+	}
+	left, n, err := s.reportValid(ctx, batch[half:])
+	return left, reported + n, err
+}
+
+// lockOutbox locks outbox by path and returns function unlocking it
+func lockOutbox(path string) func() {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	mu, _ := outboxLocks.LoadOrStore(path, new(sync.Mutex))
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// outbox returns path of outbox file of the user
+func (s *PlaysService) outbox() (string, error) {
+	if s.client.userID == 0 {
+		return "", ErrNoUserID
+	}
+
+	dir := filepath.Join(s.client.config.Output, ".plays")
+	if s.client.playReports != nil && s.client.playReports.Dir != "" {
+		dir = s.client.playReports.Dir
+	}
+	return filepath.Join(dir, fmt.Sprintf("%d.jsonl", s.client.userID)), nil
+}
+
+// appendOutbox writes plays to the end of the outbox
+func (s *PlaysService) appendOutbox(plays []Play) error {
+	path, err := s.outbox()
+	if err != nil {
+		return err
+	}
+	defer lockOutbox(path)()
+
+	b, err := encodePlays(plays)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(b); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readOutbox returns plays of the outbox by path. Lines that can't be
+// decoded, e.g. partially written ones, are skipped.
+func (s *PlaysService) readOutbox(path string) ([]Play, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var plays []Play
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var play Play
+		if err := json.Unmarshal(scanner.Bytes(), &play); err != nil {
+			s.client.logger.Warn("broken play in outbox, skipped", "path", file.Name(), "error", err)
+			continue
+		}
+		plays = append(plays, play)
+	}
+	return plays, scanner.Err()
+}
+
+// writeOutbox replaces plays of the outbox by path with plays
+func writeOutbox(path string, plays []Play) error {
+	if len(plays) == 0 {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	b, err := encodePlays(plays)
+	if err != nil {
+		return err
+	}
+
+	// Outbox is replaced at once, so plays are never lost half written
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// encodePlays returns plays as JSON lines
+func encodePlays(plays []Play) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, play := range plays {
+		if err := enc.Encode(play); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// newPlayID returns random id of play
+func newPlayID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,230 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaysService_Report(t *testing.T) {
+	setup()
+	defer teardown()
+
+	want := &PlaysResp{}
+	want.InvocationInfo.ReqID = "Plays.Report"
+	want.Result = "ok"
+
+	play := Play{
+		TrackID:            "1",
+		AlbumID:            "10",
+		PlaylistID:         PlaylistPlayID(userID, 1004),
+		From:               "playlist",
+		PlayID:             "play",
+		Timestamp:          time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		EndPositionSeconds: 180,
+		TotalPlayedSeconds: 180,
+		TrackLengthSeconds: 180,
+	}
+
+	mux.HandleFunc("/plays", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		_, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("client-now"))
+		assert.NoError(t, err)
+
+		var body struct {
+			Plays []Play `json:"plays"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []Play{play}, body.Plays)
+
+		b, err := json.Marshal(want)
+		assert.NoError(t, err)
+		fmt.Fprint(w, string(b))
+	})
+
+	result, _, err := client.Plays().Report(context.Background(), play)
+
+	assert.NoError(t, err)
+	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+	assert.Equal(t, "2000:1004", play.PlaylistID)
+}
+
+func TestPlaysService_Record(t *testing.T) {
+	setup(Plays(&PlaysOptions{Dir: t.TempDir(), BatchSize: 2}))
+	defer teardown()
+
+	down := true
+	var batches [][]Play
+	mux.HandleFunc("/plays", func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		var body struct {
+			Plays []Play `json:"plays"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		batches = append(batches, body.Plays)
+		fmt.Fprint(w, `{"result":"ok"}`)
+	})
+
+	ctx := context.Background()
+	err := client.Plays().Record(ctx, Play{TrackID: "1"}, Play{TrackID: "2"})
+	assert.Error(t, err)
+	err = client.Plays().Record(ctx, Play{TrackID: "3"})
+	assert.Error(t, err)
+
+	pending, err := client.Plays().Pending()
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 3) {
+		assert.NotEmpty(t, pending[0].PlayID)
+		assert.NotEqual(t, pending[0].PlayID, pending[1].PlayID)
+		assert.False(t, pending[0].Timestamp.IsZero())
+		assert.Equal(t, "3", pending[2].TrackID)
+	}
+
+	down = false
+	reported, err := client.Plays().Flush(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, reported)
+	if assert.Len(t, batches, 2) {
+		assert.Len(t, batches[0], 2)
+		assert.Equal(t, pending[2].PlayID, batches[1][0].PlayID)
+	}
+
+	pending, err = client.Plays().Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPlaysService_ReportIsNotRetried(t *testing.T) {
+	setup(Retry(&RetryOptions{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	defer teardown()
+
+	attempts := 0
+	mux.HandleFunc("/plays", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, _, err := client.Plays().Report(context.Background(), Play{TrackID: "1", PlayID: "play"})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestPlaysService_RecordWithoutUserID(t *testing.T) {
+	dir := t.TempDir()
+	setup(Plays(&PlaysOptions{Dir: dir}), func(c *Client) { c.SetUserID(0) })
+	defer teardown()
+
+	err := client.Plays().Record(context.Background(), Play{TrackID: "1"})
+	assert.ErrorIs(t, err, ErrNoUserID)
+
+	_, err = client.Plays().Pending()
+	assert.ErrorIs(t, err, ErrNoUserID)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPlaysService_RecordDropsRejected(t *testing.T) {
+	setup(Plays(&PlaysOptions{Dir: t.TempDir()}))
+	defer teardown()
+
+	mux.HandleFunc("/plays", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"name":"validate","message":"track not found"}}`)
+	})
+
+	err := client.Plays().Record(context.Background(), Play{TrackID: "unknown"})
+	assert.NoError(t, err)
+
+	pending, err := client.Plays().Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPlaysService_RecordDropsOnlyRejectedPlays(t *testing.T) {
+	setup(Plays(&PlaysOptions{Dir: t.TempDir()}))
+	defer teardown()
+
+	var reported []string
+	mux.HandleFunc("/plays", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Plays []Play `json:"plays"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		for _, play := range body.Plays {
+			if play.TrackID == "unknown" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"name":"validate","message":"track not found"}}`)
+				return
+			}
+		}
+		for _, play := range body.Plays {
+			reported = append(reported, play.TrackID)
+		}
+		fmt.Fprint(w, `{"result":"ok"}`)
+	})
+
+	err := client.Plays().Record(
+		context.Background(),
+		Play{TrackID: "1"}, Play{TrackID: "unknown"}, Play{TrackID: "2"}, Play{TrackID: "3"},
+	)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, reported)
+
+	pending, err := client.Plays().Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPlaysService_RecordSharedOutbox(t *testing.T) {
+	setup(Plays(&PlaysOptions{Dir: t.TempDir()}))
+	defer teardown()
+
+	var mu sync.Mutex
+	reported := map[string]int{}
+	mux.HandleFunc("/plays", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Plays []Play `json:"plays"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		for _, play := range body.Plays {
+			reported[play.PlayID]++
+		}
+		mu.Unlock()
+		fmt.Fprint(w, `{"result":"ok"}`)
+	})
+
+	// Clients of the same user share the outbox
+	clients := []*Client{client, client.WithToken(accessToken, userID)}
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := clients[i%2].Plays().Record(context.Background(), Play{TrackID: fmt.Sprint(i)})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, reported, 20)
+	for playID, n := range reported {
+		assert.Equal(t, 1, n, playID)
+	}
+}
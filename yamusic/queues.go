@@ -0,0 +1,207 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// defaultDevice describes the client in queue requests if no device is set
+const defaultDevice = "os=Go; os_version=; manufacturer=; model=yamusic; clid=; device_id=yamusic; uuid=yamusic"
+
+type (
+	// QueuesService is a service to deal with play queues synchronised
+	// between devices
+	QueuesService struct {
+		client *Client
+	}
+	// QueueContext describes what queue is played from
+	QueueContext struct {
+		// Type is "playlist", "album", "artist", "radio", "my_music",
+		// "search" or "various"
+		Type        string `json:"type"`
+		ID          string `json:"id,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+	// QueueTrack is track of queue
+	QueueTrack struct {
+		TrackID string `json:"trackId"`
+		AlbumID string `json:"albumId"`
+		From    string `json:"from"`
+	}
+	// Queue is tracks played on a device and index of the current one
+	Queue struct {
+		ID           string       `json:"id"`
+		Context      QueueContext `json:"context"`
+		Tracks       []QueueTrack `json:"tracks"`
+		CurrentIndex int          `json:"currentIndex"`
+		Modified     time.Time    `json:"modified"`
+		From         string       `json:"from"`
+	}
+	// QueueItem is queue in list of queues
+	QueueItem struct {
+		ID       string       `json:"id"`
+		Context  QueueContext `json:"context"`
+		Modified time.Time    `json:"modified"`
+	}
+	// QueuesListResp describes list queues response
+	QueuesListResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Queues []QueueItem `json:"queues"`
+		} `json:"result"`
+	}
+	// QueueResp describes get queue response
+	QueueResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         Queue          `json:"result"`
+	}
+	// QueueCreateResp describes create queue response
+	QueueCreateResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			ID                string `json:"id"`
+			MostRecentQueueID string `json:"mostRecentQueueId"`
+		} `json:"result"`
+	}
+	// QueueUpdateResp describes update queue position response
+	QueueUpdateResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Status string `json:"status"`
+		} `json:"result"`
+	}
+)
+
+// Device sets description of the device sent in queue requests,
+// e.g. "os=Linux; os_version=6.1; manufacturer=; model=player; clid=;
+// device_id=123; uuid=123"
+func Device(device string) func(*Client) {
+	return func(c *Client) {
+		c.device = device
+	}
+}
+
+// CurrentTrack returns the track queue is playing
+func (q *Queue) CurrentTrack() (QueueTrack, bool) {
+	if q.CurrentIndex < 0 || q.CurrentIndex >= len(q.Tracks) {
+		return QueueTrack{}, false
+	}
+	return q.Tracks[q.CurrentIndex], true
+}
+
+// List returns queues of the user from all devices, the most recent first
+func (s *QueuesService) List(ctx context.Context) (*QueuesListResp, *http.Response, error) {
+	req, err := s.newRequest(http.MethodGet, "queues", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queues := new(QueuesListResp)
+	resp, err := s.client.Do(ctx, req, queues)
+	return queues, resp, err
+}
+
+// Get returns queue by id with its tracks and current index
+func (s *QueuesService) Get(ctx context.Context, id string) (*QueueResp, *http.Response, error) {
+	req, err := s.newRequest(http.MethodGet, "queues/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	queue := new(QueueResp)
+	resp, err := s.client.Do(ctx, req, queue)
+	return queue, resp, err
+}
+
+// Latest returns the most recently modified queue of the user.
+// It returns ErrNotFound if the user has no queues.
+func (s *QueuesService) Latest(ctx context.Context) (*Queue, error) {
+	queues, _, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(queues.Result.Queues) == 0 {
+		return nil, fmt.Errorf("queues of user %d: %w", s.client.userID, ErrNotFound)
+	}
+
+	latest := queues.Result.Queues[0]
+	for _, queue := range queues.Result.Queues[1:] {
+		if queue.Modified.After(latest.Modified) {
+			latest = queue
+		}
+	}
+
+	queue, _, err := s.Get(ctx, latest.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &queue.Result, nil
+}
+
+// Create creates queue of tracks on the device and returns its id.
+// ID and Modified of queue are ignored.
+func (s *QueuesService) Create(ctx context.Context, queue Queue) (*QueueCreateResp, *http.Response, error) {
+	body := struct {
+		Context      QueueContext `json:"context"`
+		Tracks       []QueueTrack `json:"tracks"`
+		CurrentIndex int          `json:"currentIndex"`
+		From         string       `json:"from,omitempty"`
+	}{queue.Context, queue.Tracks, queue.CurrentIndex, queue.From}
+
+	req, err := s.newRequest(http.MethodPost, "queues", body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	created := new(QueueCreateResp)
+	resp, err := s.client.Do(ctx, req, created)
+	return created, resp, err
+}
+
+// UpdatePosition sets index of the current track of queue. Interactive
+// tells whether position is changed by the user, not by playback.
+func (s *QueuesService) UpdatePosition(
+	ctx context.Context,
+	id string,
+	index int,
+	interactive bool,
+) (*QueueUpdateResp, *http.Response, error) {
+	queryParams := url.Values{}
+	queryParams.Set("currentIndex", strconv.Itoa(index))
+	queryParams.Set("isInteractive", strconv.FormatBool(interactive))
+
+	uri := fmt.Sprintf("queues/%s/update-position?%v", url.PathEscape(id), queryParams.Encode())
+	req, err := s.newRequest(http.MethodPost, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated := new(QueueUpdateResp)
+	resp, err := s.client.Do(withRetrySafe(ctx), req, updated)
+	return updated, resp, err
+}
+
+// newRequest returns request with description of the device queues
+// require
+func (s *QueuesService) newRequest(method, uri string, body interface{}) (*http.Request, error) {
+	req, err := s.client.NewRequest(method, uri, body)
+	if err != nil {
+		return nil, err
+	}
+
+	device := s.client.device
+	if device == "" {
+		device = defaultDevice
+	}
+	req.Header.Set("X-Yandex-Music-Device", device)
+	return req, nil
+}
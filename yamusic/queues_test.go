@@ -0,0 +1,93 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueuesService_Latest(t *testing.T) {
+	setup(Device("os=Linux; device_id=home"))
+	defer teardown()
+
+	mux.HandleFunc("/queues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "os=Linux; device_id=home", r.Header.Get("X-Yandex-Music-Device"))
+		fmt.Fprint(w, `{"result":{"queues":[
+			{"id":"old","context":{"type":"playlist"},"modified":"2024-01-01T10:00:00Z"},
+			{"id":"new","context":{"type":"radio"},"modified":"2024-01-02T10:00:00Z"}
+		]}}`)
+	})
+	mux.HandleFunc("/queues/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"id":"new","context":{"type":"radio"},"currentIndex":1,
+			"tracks":[{"trackId":"1","albumId":"10"},{"trackId":"2","albumId":"20"}]}}`)
+	})
+
+	queue, err := client.Queues().Latest(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new", queue.ID)
+	current, ok := queue.CurrentTrack()
+	assert.True(t, ok)
+	assert.Equal(t, "2", current.TrackID)
+}
+
+func TestQueuesService_LatestWithoutQueues(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/queues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, defaultDevice, r.Header.Get("X-Yandex-Music-Device"))
+		fmt.Fprint(w, `{"result":{"queues":[]}}`)
+	})
+
+	_, err := client.Queues().Latest(context.Background())
+
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestQueuesService_Create(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/queues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.NotContains(t, body, "id")
+		assert.Equal(t, 1.0, body["currentIndex"])
+		fmt.Fprint(w, `{"result":{"id":"q1","mostRecentQueueId":"q1"}}`)
+	})
+
+	created, _, err := client.Queues().Create(context.Background(), Queue{
+		ID:           "ignored",
+		Context:      QueueContext{Type: "playlist", ID: "2000:1001"},
+		Tracks:       []QueueTrack{{TrackID: "1", AlbumID: "10"}, {TrackID: "2", AlbumID: "20"}},
+		CurrentIndex: 1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "q1", created.Result.ID)
+}
+
+func TestQueuesService_UpdatePosition(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/queues/q1/update-position", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "3", r.URL.Query().Get("currentIndex"))
+		assert.Equal(t, "false", r.URL.Query().Get("isInteractive"))
+		fmt.Fprint(w, `{"result":{"status":"ok"}}`)
+	})
+
+	updated, _, err := client.Queues().UpdatePosition(context.Background(), "q1", 3, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", updated.Result.Status)
+}
@@ -0,0 +1,125 @@
+package yamusic
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// EndpointClass is a group of endpoints sharing one rate limit budget
+type EndpointClass int
+
+const (
+	// EndpointMetadata are regular API calls: tracks, playlists, search etc.
+	EndpointMetadata EndpointClass = iota
+	// EndpointDownloadInfo are tracks/{id}/download-info calls and requests
+	// of DownloadInfoURL returned by them
+	EndpointDownloadInfo
+	// EndpointStorage are downloads of audio files from storage hosts
+	EndpointStorage
+)
+
+type (
+	// RateBudget is a token bucket: PerSecond tokens are added every second
+	// and at most Burst requests can be sent at once
+	RateBudget struct {
+		PerSecond float64
+		Burst     int
+	}
+	// RateLimitOptions are budgets of endpoint classes.
+	// Nil budget means default one.
+	RateLimitOptions struct {
+		Metadata     *RateBudget
+		DownloadInfo *RateBudget
+		Storage      *RateBudget
+	}
+	// RateLimiter limits requests per endpoint class. One RateLimiter can be
+	// shared by several clients to stay within the same budgets.
+	RateLimiter struct {
+		limiters map[EndpointClass]*rate.Limiter
+	}
+	// RateLimitDoer is a Doer that waits for RateLimiter before every request
+	RateLimitDoer struct {
+		doer    Doer
+		limiter *RateLimiter
+	}
+)
+
+var (
+	defaultMetadataBudget     = RateBudget{PerSecond: 10, Burst: 10}
+	defaultDownloadInfoBudget = RateBudget{PerSecond: 5, Burst: 5}
+	defaultStorageBudget      = RateBudget{PerSecond: 2, Burst: 2}
+)
+
+// NewRateLimiter returns a new RateLimiter. If opts is nil,
+// default budgets are used.
+func NewRateLimiter(opts *RateLimitOptions) *RateLimiter {
+	if opts == nil {
+		opts = &RateLimitOptions{}
+	}
+
+	newLimiter := func(budget *RateBudget, def RateBudget) *rate.Limiter {
+		if budget == nil {
+			budget = &def
+		}
+		if budget.PerSecond <= 0 {
+			return rate.NewLimiter(rate.Inf, 0)
+		}
+		return rate.NewLimiter(rate.Limit(budget.PerSecond), max(budget.Burst, 1))
+	}
+
+	return &RateLimiter{
+		limiters: map[EndpointClass]*rate.Limiter{
+			EndpointMetadata:     newLimiter(opts.Metadata, defaultMetadataBudget),
+			EndpointDownloadInfo: newLimiter(opts.DownloadInfo, defaultDownloadInfoBudget),
+			EndpointStorage:      newLimiter(opts.Storage, defaultStorageBudget),
+		},
+	}
+}
+
+// Wait blocks until request of endpoint class is allowed or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, class EndpointClass) error {
+	limiter, ok := l.limiters[class]
+	if !ok {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// RateLimit sets rate limiter for Yandex.Music client.
+// If limiter is nil, a new one with default budgets is used.
+func RateLimit(limiter *RateLimiter) func(*Client) {
+	return func(c *Client) {
+		if limiter == nil {
+			limiter = NewRateLimiter(nil)
+		}
+		c.limiter = limiter
+	}
+}
+
+// NewRateLimitDoer returns a Doer that waits for limiter before
+// sending requests made by doer
+func NewRateLimitDoer(doer Doer, limiter *RateLimiter) *RateLimitDoer {
+	return &RateLimitDoer{doer: doer, limiter: limiter}
+}
+
+// Do waits for the budget of request's endpoint class and sends request
+func (d *RateLimitDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := d.limiter.Wait(req.Context(), endpointClassOf(req)); err != nil {
+		return nil, err
+	}
+	return d.doer.Do(req)
+}
+
+// endpointClassOf returns endpoint class of request by its path
+func endpointClassOf(req *http.Request) EndpointClass {
+	switch path := req.URL.Path; {
+	case strings.Contains(path, "/get-mp3/"):
+		return EndpointStorage
+	case strings.Contains(path, "download-info"):
+		return EndpointDownloadInfo
+	}
+	return EndpointMetadata
+}
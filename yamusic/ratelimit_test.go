@@ -0,0 +1,97 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_SharedByServices(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimitOptions{
+		Metadata: &RateBudget{PerSecond: 20, Burst: 1},
+	})
+	setup(RateLimit(limiter))
+	defer teardown()
+
+	for _, path := range []string{"/genres", "/feed", "/account/status"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			b, err := json.Marshal(&GenresListResp{})
+			assert.NoError(t, err)
+			fmt.Fprint(w, string(b))
+		})
+	}
+
+	start := time.Now()
+
+	_, _, err := client.Genres().List(context.Background())
+	assert.NoError(t, err)
+	_, _, err = client.Feed().Get(context.Background())
+	assert.NoError(t, err)
+	_, _, err = client.Account().GetStatus(context.Background())
+	assert.NoError(t, err)
+
+	// 1 request of burst and 2 requests 50ms apart
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestRateLimiter_WaitRespectsContext(t *testing.T) {
+	limiter := NewRateLimiter(&RateLimitOptions{
+		Storage: &RateBudget{PerSecond: 0.001, Burst: 1},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, limiter.Wait(ctx, EndpointStorage))
+	assert.Error(t, limiter.Wait(ctx, EndpointStorage))
+	// other classes have their own budgets
+	assert.NoError(t, limiter.Wait(ctx, EndpointMetadata))
+}
+
+func TestRateLimitDoer_ClassBudgets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	doer := NewRateLimitDoer(http.DefaultClient, NewRateLimiter(&RateLimitOptions{
+		DownloadInfo: &RateBudget{PerSecond: 0.001, Burst: 1},
+	}))
+
+	do := func(path string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+		assert.NoError(t, err)
+		resp, err := doer.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	assert.NoError(t, do("/tracks/1/download-info"))
+	assert.Error(t, do("/download-info/abc/2"))
+	assert.NoError(t, do("/get-mp3/sign/ts/path"))
+	assert.NoError(t, do("/tracks/1"))
+}
+
+func TestEndpointClassOf(t *testing.T) {
+	cases := map[string]EndpointClass{
+		"https://api.music.yandex.net/tracks/1":                         EndpointMetadata,
+		"https://api.music.yandex.net/users/1/playlists/list":           EndpointMetadata,
+		"https://api.music.yandex.net/tracks/1/download-info":           EndpointDownloadInfo,
+		"https://storage.mds.yandex.net/download-info/53090/2?sign=abc": EndpointDownloadInfo,
+		"https://s1.storage.yandex.net/get-mp3/sign/ts/rmusic/U2FsdGVk": EndpointStorage,
+	}
+
+	for uri, want := range cases {
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, want, endpointClassOf(req), uri)
+	}
+}
@@ -0,0 +1,309 @@
+// Package recorder provides a record/replay yamusic.Doer. It records real
+// HTTP sessions with Yandex.Music API once into cassette files and replays
+// them deterministically in tests and offline development.
+//
+//	rec, err := recorder.New("testdata/cassettes/tracks", recorder.ModeFromEnv(), nil)
+//	defer rec.Stop()
+//	client := yamusic.NewClient(yamusic.HTTPClient(rec), ...)
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode is a mode of Recorder
+type Mode int
+
+const (
+	// ModeReplay serves responses from cassette only and fails on requests
+	// that weren't recorded
+	ModeReplay Mode = iota
+	// ModeRecord sends all requests to real API and writes them into
+	// a new cassette on Stop
+	ModeRecord
+	// ModeReplayOrRecord serves recorded requests from cassette and records
+	// the new ones
+	ModeReplayOrRecord
+)
+
+// Scrubbed replaces secrets in recorded interactions
+const Scrubbed = "<scrubbed>"
+
+// ErrNoInteraction is returned in replay mode for a request
+// that has no recorded interaction
+var ErrNoInteraction = errors.New("recorder: no recorded interaction")
+
+type (
+	// Doer is an interface that can do http request, the same as yamusic.Doer
+	Doer interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+	// Options configures Recorder
+	Options struct {
+		// Real is used to send requests in record modes.
+		// Default is http.DefaultClient.
+		Real Doer
+		// Secrets are replaced with Scrubbed in URLs and bodies of recorded
+		// interactions, e.g. OAuth token or user's login
+		Secrets []string
+		// Headers are request headers to record in addition to Content-Type.
+		// Authorization header is never recorded.
+		Headers []string
+	}
+	// Recorder is a Doer recording and replaying HTTP interactions
+	Recorder struct {
+		path string
+		mode Mode
+		opts Options
+
+		mu       sync.Mutex
+		cassette *Cassette
+		used     []bool
+		changed  bool
+	}
+	// Cassette is a file with recorded interactions
+	Cassette struct {
+		Interactions []Interaction `yaml:"interactions"`
+	}
+	// Interaction is a recorded request and its response
+	Interaction struct {
+		Request  Request  `yaml:"request"`
+		Response Response `yaml:"response"`
+	}
+	// Request is a recorded request
+	Request struct {
+		Method  string              `yaml:"method"`
+		URL     string              `yaml:"url"`
+		Headers map[string][]string `yaml:"headers,omitempty"`
+		Body    string              `yaml:"body,omitempty"`
+	}
+	// Response is a recorded response
+	Response struct {
+		Status  int                 `yaml:"status"`
+		Headers map[string][]string `yaml:"headers,omitempty"`
+		Body    string              `yaml:"body,omitempty"`
+	}
+)
+
+// ModeFromEnv returns ModeRecord if YAMUSIC_RECORD environment variable
+// is set to "1" or "true" and ModeReplay otherwise
+func ModeFromEnv() Mode {
+	switch strings.ToLower(os.Getenv("YAMUSIC_RECORD")) {
+	case "1", "true":
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// New returns Recorder using cassette by path. Extension .yaml is added
+// to path if it has no one. In replay modes cassette is loaded from file.
+// If opts is nil, default options are used.
+func New(path string, mode Mode, opts *Options) (*Recorder, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if filepath.Ext(path) == "" {
+		path += ".yaml"
+	}
+
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		opts:     *opts,
+		cassette: new(Cassette),
+	}
+	if r.opts.Real == nil {
+		r.opts.Real = http.DefaultClient
+	}
+
+	if mode != ModeRecord {
+		b, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.Unmarshal(b, r.cassette); err != nil {
+				return nil, fmt.Errorf("recorder: decode %s: %w", path, err)
+			}
+		case !os.IsNotExist(err) || mode == ModeReplay:
+			return nil, err
+		}
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+
+	return r, nil
+}
+
+// Do replays recorded response of request or sends request
+// and records the interaction depending on mode
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	recorded, err := r.newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode != ModeRecord {
+		if interaction, ok := r.take(recorded); ok {
+			return interaction.Response.toHTTP(req), nil
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+		}
+	}
+
+	resp, err := r.opts.Real.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: r.scrubHeaders(resp.Header, []string{"Content-Type", "Retry-After"}),
+			Body:    r.scrub(string(body)),
+		},
+	})
+	r.used = append(r.used, true)
+	r.changed = true
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// Stop writes cassette to file if new interactions were recorded
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.changed {
+		return nil
+	}
+
+	b, err := yaml.Marshal(r.cassette)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, b, 0o644); err != nil {
+		return err
+	}
+
+	r.changed = false
+	return nil
+}
+
+// Cassette returns recorded interactions
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// take returns the first unused interaction matching request and marks it
+// as used, so repeated requests are replayed in recorded order
+func (r *Recorder) take(req Request) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if !r.used[i] && interaction.Request.matches(req) {
+			r.used[i] = true
+			return interaction, true
+		}
+	}
+	return Interaction{}, false
+}
+
+// newRequest returns scrubbed copy of request to record or match
+func (r *Recorder) newRequest(req *http.Request) (Request, error) {
+	recorded := Request{
+		Method:  req.Method,
+		URL:     r.scrub(req.URL.String()),
+		Headers: r.scrubHeaders(req.Header, append([]string{"Content-Type"}, r.opts.Headers...)),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return Request{}, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		recorded.Body = r.scrub(string(body))
+	}
+
+	return recorded, nil
+}
+
+// scrub replaces secrets in s
+func (r *Recorder) scrub(s string) string {
+	for _, secret := range r.opts.Secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Scrubbed)
+		}
+	}
+	return s
+}
+
+// scrubHeaders returns scrubbed copy of headers having names
+func (r *Recorder) scrubHeaders(header http.Header, names []string) map[string][]string {
+	var headers map[string][]string
+	for _, name := range names {
+		if strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string][]string)
+		}
+		for _, v := range values {
+			headers[http.CanonicalHeaderKey(name)] = append(headers[http.CanonicalHeaderKey(name)], r.scrub(v))
+		}
+	}
+	return headers
+}
+
+// matches reports whether recorded request is the same as req
+func (r Request) matches(req Request) bool {
+	return r.Method == req.Method && r.URL == req.URL && r.Body == req.Body
+}
+
+// toHTTP returns HTTP response of recorded one
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	header := make(http.Header, len(r.Headers))
+	for name, values := range r.Headers {
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,108 @@
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const token = "AQAAAAAsecret"
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "OAuth "+token, r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"result":{"n":%d,"body":%q,"token":%q}}`, requests, body, token)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "session")
+
+	do := func(doer Doer, body string) string {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/tracks", strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "OAuth "+token)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		resp, err := doer.Do(req)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		b, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(b)
+	}
+
+	rec, err := New(path, ModeRecord, &Options{Secrets: []string{token}})
+	assert.NoError(t, err)
+	first := do(rec, "track-ids=1")
+	second := do(rec, "track-ids=1")
+	other := do(rec, "track-ids=2")
+	assert.NoError(t, rec.Stop())
+	assert.Equal(t, 3, requests)
+	assert.Contains(t, first, token)
+
+	b, err := os.ReadFile(path + ".yaml")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), token)
+	assert.Contains(t, string(b), Scrubbed)
+
+	rec, err = New(path, ModeReplay, nil)
+	assert.NoError(t, err)
+	scrubbed := strings.ReplaceAll(first, token, Scrubbed)
+	assert.Equal(t, scrubbed, do(rec, "track-ids=1"))
+	assert.Equal(t, strings.ReplaceAll(second, token, Scrubbed), do(rec, "track-ids=1"))
+	assert.Equal(t, strings.ReplaceAll(other, token, Scrubbed), do(rec, "track-ids=2"))
+	assert.Equal(t, 3, requests)
+
+	// All recorded interactions are used
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/tracks", strings.NewReader("track-ids=1"))
+	assert.NoError(t, err)
+	_, err = rec.Do(req)
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+func TestRecorder_ReplayOrRecord(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "session.yaml")
+
+	for i := 0; i < 2; i++ {
+		rec, err := New(path, ModeReplayOrRecord, nil)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/genres", nil)
+		assert.NoError(t, err)
+		resp, err := rec.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		assert.NoError(t, rec.Stop())
+	}
+
+	assert.Equal(t, 1, requests)
+}
+
+func TestNew_MissingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing"), ModeReplay, nil)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,261 @@
+package yamusic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type (
+	// RetryOptions configures retrying of failed requests
+	RetryOptions struct {
+		// MaxAttempts is the total number of attempts including the first one.
+		// Default is 3.
+		MaxAttempts int
+		// MinBackoff is the delay before the first retry. Every next delay
+		// is doubled. Default is 500ms.
+		MinBackoff time.Duration
+		// MaxBackoff caps the delay between attempts, including the one
+		// requested by Retry-After header. Default is 30s.
+		MaxBackoff time.Duration
+		// Jitter is a fraction of the delay that is randomized, from 0 to 1.
+		// E.g. 0.2 turns 1s delay into something between 0.8s and 1.2s.
+		Jitter float64
+		// IdempotentMethods are HTTP methods that are safe to retry.
+		// Default is GET, HEAD, OPTIONS, PUT and DELETE.
+		IdempotentMethods []string
+		// IgnoreRetryAfter disables honouring of Retry-After header
+		// of 429 and 503 responses
+		IgnoreRetryAfter bool
+	}
+	// RetryDoer is a Doer that retries requests on network errors,
+	// 5xx and 429 responses with exponential backoff
+	RetryDoer struct {
+		doer Doer
+		opts RetryOptions
+	}
+	// retrySafeKey is a context key marking non-idempotent request as safe
+	// to retry
+	retrySafeKey struct{}
+	// retryUnappliedKey is a context key marking non-idempotent request
+	// to be retried only if it wasn't applied
+	retryUnappliedKey struct{}
+)
+
+var defaultIdempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// NewRetryDoer returns a Doer that retries requests made by doer.
+// If opts is nil, default options are used.
+func NewRetryDoer(doer Doer, opts *RetryOptions) *RetryDoer {
+	if opts == nil {
+		opts = &RetryOptions{}
+	}
+
+	r := &RetryDoer{doer: doer, opts: *opts}
+	if r.opts.MaxAttempts <= 0 {
+		r.opts.MaxAttempts = 3
+	}
+	if r.opts.MinBackoff <= 0 {
+		r.opts.MinBackoff = 500 * time.Millisecond
+	}
+	if r.opts.MaxBackoff <= 0 {
+		r.opts.MaxBackoff = 30 * time.Second
+	}
+	if r.opts.MaxBackoff < r.opts.MinBackoff {
+		r.opts.MaxBackoff = r.opts.MinBackoff
+	}
+	if r.opts.IdempotentMethods == nil {
+		r.opts.IdempotentMethods = defaultIdempotentMethods
+	}
+
+	return r
+}
+
+// Retry sets retrying of failed requests for Yandex.Music client.
+// If opts is nil, default options are used.
+func Retry(opts *RetryOptions) func(*Client) {
+	return func(c *Client) {
+		if opts == nil {
+			opts = &RetryOptions{}
+		}
+		c.retry = opts
+	}
+}
+
+// withRetrySafe marks request made with ctx as safe to retry
+// even if its method is not idempotent. It's for POST requests that only
+// read, e.g. getting tracks by ids, or that set a value as a whole, so
+// applying them twice is the same as applying them once.
+func withRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+// withRetryUnapplied marks request made with ctx to be retried only if
+// the server surely didn't apply it: on 429, on 503 with Retry-After and
+// on errors of connecting. It's for changes relative to revision of
+// playlist, since applied change can't be applied again.
+func withRetryUnapplied(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryUnappliedKey{}, true)
+}
+
+// Do sends request and retries it if it failed with transient error
+func (r *RetryDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	unapplied, _ := ctx.Value(retryUnappliedKey{}).(bool)
+
+	if !r.retryable(req) {
+		return r.doer.Do(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := r.doer.Do(req)
+
+		delay, retry := r.shouldRetry(ctx, resp, err, unapplied)
+		if !retry || attempt >= r.opts.MaxAttempts {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain body to let the connection be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if delay == 0 {
+			delay = r.backoff(attempt)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether request can be sent more than once
+func (r *RetryDoer) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if safe, _ := req.Context().Value(retrySafeKey{}).(bool); safe {
+		return true
+	}
+	if unapplied, _ := req.Context().Value(retryUnappliedKey{}).(bool); unapplied {
+		return true
+	}
+	return slices.Contains(r.opts.IdempotentMethods, req.Method)
+}
+
+// shouldRetry reports whether request should be retried and how long to
+// wait before it if the server asked for it with Retry-After header.
+// If unapplied is set, only failures of request that wasn't applied
+// are retried.
+func (r *RetryDoer) shouldRetry(
+	ctx context.Context,
+	resp *http.Response,
+	err error,
+	unapplied bool,
+) (time.Duration, bool) {
+	if ctx.Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		if unapplied {
+			return 0, notConnected(err)
+		}
+		return 0, !errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded)
+	}
+
+	// Request answered with 429 or 503 with Retry-After isn't processed,
+	// other failed requests could be applied before failing
+	if unapplied && resp.StatusCode != http.StatusTooManyRequests &&
+		(resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "") {
+		return 0, false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if r.opts.IgnoreRetryAfter {
+			return 0, true
+		}
+		delay := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return min(delay, r.opts.MaxBackoff), true
+	case http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusGatewayTimeout:
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// notConnected reports whether err happened before request was sent,
+// e.g. connection was refused or host wasn't resolved
+func notConnected(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) && opErr.Op == "dial" || errors.As(err, &dnsErr)
+}
+
+// backoff returns delay before next attempt
+func (r *RetryDoer) backoff(attempt int) time.Duration {
+	// Delay is compared before shifting, so it can't overflow
+	delay := r.opts.MaxBackoff
+	if shift := attempt - 1; shift < 63 && r.opts.MinBackoff <= r.opts.MaxBackoff>>shift {
+		delay = r.opts.MinBackoff << shift
+	}
+
+	if r.opts.Jitter > 0 {
+		jitter := min(r.opts.Jitter, 1)
+		delay = time.Duration(float64(delay) * (1 - jitter + 2*jitter*rand.Float64()))
+	}
+
+	return delay
+}
+
+// parseRetryAfter parses value of Retry-After header that is either
+// number of seconds or HTTP date. It returns 0 if value is invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,229 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDoer_RetriesServerErrors(t *testing.T) {
+	setup(Retry(&RetryOptions{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	defer teardown()
+
+	want := &GenresListResp{}
+	want.InvocationInfo.ReqID = "Genres.List"
+
+	attempts := 0
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		b, err := json.Marshal(want)
+		assert.NoError(t, err)
+		fmt.Fprint(w, string(b))
+	})
+
+	result, _, err := client.Genres().List(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
+}
+
+func TestRetryDoer_GivesUp(t *testing.T) {
+	setup(Retry(&RetryOptions{MaxAttempts: 2, MinBackoff: time.Millisecond}))
+	defer teardown()
+
+	attempts := 0
+	mux.HandleFunc("/genres", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, _, err := client.Genres().List(context.Background())
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryDoer_DoesNotRetryNotIdempotent(t *testing.T) {
+	setup(Retry(&RetryOptions{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	defer teardown()
+
+	kind := 1004
+
+	attempts := 0
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/name", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusInternalServerError)
+		},
+	)
+
+	_, _, err := client.Playlists().Rename(context.Background(), kind, "newValue")
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryDoer_ChangeWithRevision(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		attempts   int
+	}{
+		{"too many requests", http.StatusTooManyRequests, "", 2},
+		{"unavailable with retry after", http.StatusServiceUnavailable, "0", 2},
+		// Change may be applied even if the response is lost, so retrying
+		// it would answer ErrRevisionConflict for a successful change
+		{"unavailable", http.StatusServiceUnavailable, "", 1},
+		{"server error", http.StatusInternalServerError, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(Retry(&RetryOptions{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+			defer teardown()
+
+			kind := 1004
+			attempts := 0
+			mux.HandleFunc(
+				fmt.Sprintf("/users/%v/playlists/%v/change-relative", userID, kind),
+				func(w http.ResponseWriter, r *http.Request) {
+					attempts++
+					if attempts == 1 {
+						if tt.retryAfter != "" {
+							w.Header().Set("Retry-After", tt.retryAfter)
+						}
+						w.WriteHeader(tt.status)
+						return
+					}
+					fmt.Fprintf(w, `{"result":{"kind":%v,"revision":8}}`, kind)
+				},
+			)
+
+			_, _, err := client.Playlists().AddTracks(
+				context.Background(),
+				kind,
+				7,
+				[]PlaylistsTrack{{ID: 1, AlbumID: 1}},
+				nil,
+			)
+
+			assert.Equal(t, tt.attempts, attempts)
+			assert.Equal(t, tt.attempts == 1, err != nil)
+		})
+	}
+}
+
+// refusingDoer fails the first request as if connection was refused
+type refusingDoer struct {
+	refused bool
+}
+
+func (d *refusingDoer) Do(req *http.Request) (*http.Response, error) {
+	if !d.refused {
+		d.refused = true
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func TestRetryDoer_RetriesChangeWithRevisionNotSent(t *testing.T) {
+	setup(
+		HTTPClient(new(refusingDoer)),
+		Retry(&RetryOptions{MaxAttempts: 3, MinBackoff: time.Millisecond}),
+	)
+	defer teardown()
+
+	kind := 1004
+	attempts := 0
+	mux.HandleFunc(
+		fmt.Sprintf("/users/%v/playlists/%v/change-relative", userID, kind),
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			fmt.Fprintf(w, `{"result":{"kind":%v,"revision":8}}`, kind)
+		},
+	)
+
+	_, _, err := client.Playlists().RemoveTracks(
+		context.Background(),
+		kind,
+		7,
+		[]PlaylistsTrack{{ID: 1, AlbumID: 1}},
+		nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryDoer_StopsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	doer := NewRetryDoer(http.DefaultClient, &RetryOptions{
+		MaxAttempts: 10,
+		MinBackoff:  time.Hour,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = doer.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-1", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(
+		t,
+		90*time.Second,
+		parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now),
+	)
+}
+
+func TestRetryDoer_Backoff(t *testing.T) {
+	r := NewRetryDoer(http.DefaultClient, &RetryOptions{
+		MinBackoff: time.Second,
+		MaxBackoff: 5 * time.Second,
+	})
+
+	assert.Equal(t, time.Second, r.backoff(1))
+	assert.Equal(t, 2*time.Second, r.backoff(2))
+	assert.Equal(t, 4*time.Second, r.backoff(3))
+	assert.Equal(t, 5*time.Second, r.backoff(4))
+	assert.Equal(t, 5*time.Second, r.backoff(100))
+
+	r = NewRetryDoer(http.DefaultClient, &RetryOptions{
+		MinBackoff: time.Duration(math.MaxInt64 / 3),
+		MaxBackoff: time.Duration(math.MaxInt64),
+	})
+
+	assert.Equal(t, time.Duration(math.MaxInt64/3)*2, r.backoff(2))
+	assert.Equal(t, time.Duration(math.MaxInt64), r.backoff(3))
+}
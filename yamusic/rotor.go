@@ -0,0 +1,317 @@
+package yamusic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RotorFeedbackType is a type of event sent to station
+type RotorFeedbackType string
+
+// Types of events sent to station
+const (
+	RotorRadioStarted  RotorFeedbackType = "radioStarted"
+	RotorTrackStarted  RotorFeedbackType = "trackStarted"
+	RotorTrackFinished RotorFeedbackType = "trackFinished"
+	RotorSkip          RotorFeedbackType = "skip"
+)
+
+// MyWave is station of personal endless recommendations
+var MyWave = StationID{Type: "user", Tag: "onyourwave"}
+
+type (
+	// RotorService is a service to deal with radio stations
+	RotorService struct {
+		client *Client
+	}
+	// StationID identifies station by type and tag, e.g. genre:rock
+	StationID struct {
+		Type string `json:"type"`
+		Tag  string `json:"tag"`
+	}
+	// Station describes radio station
+	Station struct {
+		ID        StationID  `json:"id"`
+		ParentID  *StationID `json:"parentId,omitempty"`
+		Name      string     `json:"name"`
+		IDForFrom string     `json:"idForFrom"`
+		Icon      struct {
+			BackgroundColor string `json:"backgroundColor"`
+			ImageURL        string `json:"imageUrl"`
+		} `json:"icon"`
+	}
+	// StationSettings are settings of tracks station plays
+	StationSettings struct {
+		// Language is "russian", "not-russian" or "any"
+		Language string `json:"language"`
+		// MoodEnergy is "fun", "active", "calm", "sad" or "all"
+		MoodEnergy string `json:"moodEnergy"`
+		// Diversity is "favorite", "popular", "discover" or "default"
+		Diversity string `json:"diversity"`
+	}
+	// StationResult is station with its settings
+	StationResult struct {
+		Station        Station         `json:"station"`
+		Settings2      StationSettings `json:"settings2"`
+		RupTitle       string          `json:"rupTitle"`
+		RupDescription string          `json:"rupDescription"`
+	}
+	// RotorStationsResp describes get stations list and get station
+	// info response
+	RotorStationsResp struct {
+		InvocationInfo InvocationInfo  `json:"invocationInfo"`
+		Error          Error           `json:"error"`
+		Result         []StationResult `json:"result"`
+	}
+	// RotorDashboardResp describes get stations dashboard response
+	RotorDashboardResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			DashboardID string          `json:"dashboardId"`
+			Stations    []StationResult `json:"stations"`
+			Pumpkin     bool            `json:"pumpkin"`
+		} `json:"result"`
+	}
+	// RotorTracksResp describes get station tracks response
+	RotorTracksResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			ID       StationID      `json:"id"`
+			Sequence []StationTrack `json:"sequence"`
+			// BatchID identifies batch in feedback about its tracks
+			BatchID string `json:"batchId"`
+			Pumpkin bool   `json:"pumpkin"`
+		} `json:"result"`
+	}
+	// StationTrack is track in a batch of station tracks
+	StationTrack struct {
+		Type  string `json:"type"`
+		Track Track  `json:"track"`
+		Liked bool   `json:"liked"`
+	}
+	// RotorResp describes station feedback and settings change response
+	RotorResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         string         `json:"result"`
+	}
+)
+
+type (
+	// RotorTracksOptions are options of getting station tracks
+	RotorTracksOptions struct {
+		// Queue is id of the last played track, so the next batch continues
+		// after it
+		Queue string
+	}
+	// RotorFeedback is an event sent to station
+	RotorFeedback struct {
+		Type      RotorFeedbackType `json:"type"`
+		Timestamp time.Time         `json:"timestamp"`
+		// From is where radio is played from, e.g. "desktop-radio"
+		From string `json:"from,omitempty"`
+		// TrackID is id of track as trackID:albumID
+		TrackID string `json:"trackId,omitempty"`
+		// TotalPlayedSeconds is how long track was played before finish or skip
+		TotalPlayedSeconds float64 `json:"totalPlayedSeconds,omitempty"`
+		// BatchID is id of batch the track came from
+		BatchID string `json:"-"`
+	}
+)
+
+// String returns station id as type:tag
+func (id StationID) String() string {
+	return id.Type + ":" + id.Tag
+}
+
+// TrackFeedbackID returns id of track to send in feedback
+func TrackFeedbackID(track Track) string {
+	if len(track.Albums) == 0 {
+		return track.ID
+	}
+	return fmt.Sprintf("%s:%d", track.ID, track.Albums[0].ID)
+}
+
+// GetStations returns all stations. Language is language of station names,
+// e.g. "en".
+func (s *RotorService) GetStations(
+	ctx context.Context,
+	language string,
+) (*RotorStationsResp, *http.Response, error) {
+	uri := "rotor/stations/list"
+	if language != "" {
+		uri += "?language=" + url.QueryEscape(language)
+	}
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stations := new(RotorStationsResp)
+	resp, err := s.client.Do(ctx, req, stations)
+	return stations, resp, err
+}
+
+// GetDashboard returns stations recommended to the user
+func (s *RotorService) GetDashboard(ctx context.Context) (*RotorDashboardResp, *http.Response, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "rotor/stations/dashboard", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dashboard := new(RotorDashboardResp)
+	resp, err := s.client.Do(ctx, req, dashboard)
+	return dashboard, resp, err
+}
+
+// GetInfo returns station with its current settings
+func (s *RotorService) GetInfo(
+	ctx context.Context,
+	station StationID,
+) (*RotorStationsResp, *http.Response, error) {
+	uri := fmt.Sprintf("rotor/station/%v/info", station)
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := new(RotorStationsResp)
+	resp, err := s.client.Do(ctx, req, info)
+	return info, resp, err
+}
+
+// SetSettings changes settings of station for the user
+func (s *RotorService) SetSettings(
+	ctx context.Context,
+	station StationID,
+	settings StationSettings,
+) (*RotorResp, *http.Response, error) {
+	uri := fmt.Sprintf("rotor/station/%v/settings2", station)
+	req, err := s.client.NewRequest(http.MethodPost, uri, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	changed := new(RotorResp)
+	resp, err := s.client.Do(withRetrySafe(ctx), req, changed)
+	return changed, resp, err
+}
+
+// GetTracks returns the next batch of station tracks
+func (s *RotorService) GetTracks(
+	ctx context.Context,
+	station StationID,
+	opts *RotorTracksOptions,
+) (*RotorTracksResp, *http.Response, error) {
+	if opts == nil {
+		opts = &RotorTracksOptions{}
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("settings2", "true")
+	if opts.Queue != "" {
+		queryParams.Set("queue", opts.Queue)
+	}
+
+	uri := fmt.Sprintf("rotor/station/%v/tracks?%v", station, queryParams.Encode())
+	req, err := s.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracks := new(RotorTracksResp)
+	resp, err := s.client.Do(ctx, req, tracks)
+	return tracks, resp, err
+}
+
+// SendFeedback sends event to station. Current time is used
+// if event has no timestamp.
+func (s *RotorService) SendFeedback(
+	ctx context.Context,
+	station StationID,
+	feedback RotorFeedback,
+) (*RotorResp, *http.Response, error) {
+	if feedback.Timestamp.IsZero() {
+		feedback.Timestamp = time.Now()
+	}
+
+	uri := fmt.Sprintf("rotor/station/%v/feedback", station)
+	if feedback.BatchID != "" {
+		uri += "?batch-id=" + url.QueryEscape(feedback.BatchID)
+	}
+	req, err := s.client.NewRequest(http.MethodPost, uri, feedback)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	sent := new(RotorResp)
+	resp, err := s.client.Do(ctx, req, sent)
+	return sent, resp, err
+}
+
+// RadioStarted tells station that it has started playing
+func (s *RotorService) RadioStarted(
+	ctx context.Context,
+	station StationID,
+	from string,
+	batchID string,
+) (*RotorResp, *http.Response, error) {
+	return s.SendFeedback(ctx, station, RotorFeedback{
+		Type:    RotorRadioStarted,
+		From:    from,
+		BatchID: batchID,
+	})
+}
+
+// TrackStarted tells station that track has started playing
+func (s *RotorService) TrackStarted(
+	ctx context.Context,
+	station StationID,
+	track Track,
+	batchID string,
+) (*RotorResp, *http.Response, error) {
+	return s.SendFeedback(ctx, station, RotorFeedback{
+		Type:    RotorTrackStarted,
+		TrackID: TrackFeedbackID(track),
+		BatchID: batchID,
+	})
+}
+
+// TrackFinished tells station that track has been played to the end
+func (s *RotorService) TrackFinished(
+	ctx context.Context,
+	station StationID,
+	track Track,
+	played time.Duration,
+	batchID string,
+) (*RotorResp, *http.Response, error) {
+	return s.SendFeedback(ctx, station, RotorFeedback{
+		Type:               RotorTrackFinished,
+		TrackID:            TrackFeedbackID(track),
+		TotalPlayedSeconds: played.Seconds(),
+		BatchID:            batchID,
+	})
+}
+
+// Skip tells station that track has been skipped after played duration
+func (s *RotorService) Skip(
+	ctx context.Context,
+	station StationID,
+	track Track,
+	played time.Duration,
+	batchID string,
+) (*RotorResp, *http.Response, error) {
+	return s.SendFeedback(ctx, station, RotorFeedback{
+		Type:               RotorSkip,
+		TrackID:            TrackFeedbackID(track),
+		TotalPlayedSeconds: played.Seconds(),
+		BatchID:            batchID,
+	})
+}
@@ -0,0 +1,78 @@
+package yamusic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotorService_GetStations(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/rotor/stations/list", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "en", r.URL.Query().Get("language"))
+		fmt.Fprint(w, `{"result":[{"station":{"id":{"type":"genre","tag":"rock"},"name":"Rock"},
+			"settings2":{"language":"any","moodEnergy":"all","diversity":"default"}}]}`)
+	})
+
+	stations, _, err := client.Rotor().GetStations(context.Background(), "en")
+
+	assert.NoError(t, err)
+	if assert.Len(t, stations.Result, 1) {
+		assert.Equal(t, "genre:rock", stations.Result[0].Station.ID.String())
+		assert.Equal(t, "all", stations.Result[0].Settings2.MoodEnergy)
+	}
+}
+
+func TestRotorService_GetTracks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/rotor/station/user:onyourwave/tracks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("settings2"))
+		assert.Equal(t, "1:10", r.URL.Query().Get("queue"))
+		fmt.Fprint(w, `{"result":{"id":{"type":"user","tag":"onyourwave"},"batchId":"b1",
+			"sequence":[{"type":"track","track":{"id":"2"},"liked":true}]}}`)
+	})
+
+	tracks, _, err := client.Rotor().GetTracks(context.Background(), MyWave, &RotorTracksOptions{Queue: "1:10"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "b1", tracks.Result.BatchID)
+	if assert.Len(t, tracks.Result.Sequence, 1) {
+		assert.Equal(t, "2", tracks.Result.Sequence[0].Track.ID)
+		assert.True(t, tracks.Result.Sequence[0].Liked)
+	}
+}
+
+func TestRotorService_Skip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/rotor/station/genre:rock/feedback", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "b1", r.URL.Query().Get("batch-id"))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "skip", body["type"])
+		assert.Equal(t, "1:10", body["trackId"])
+		assert.Equal(t, 12.5, body["totalPlayedSeconds"])
+		assert.NotEmpty(t, body["timestamp"])
+		fmt.Fprint(w, `{"result":"ok"}`)
+	})
+
+	track := Track{ID: "1", Albums: Albums{{ID: 10}}}
+	station := StationID{Type: "genre", Tag: "rock"}
+	sent, _, err := client.Rotor().Skip(context.Background(), station, track, 12500*time.Millisecond, "b1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", sent.Result)
+}
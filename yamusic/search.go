@@ -2,7 +2,9 @@ package yamusic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -10,13 +12,26 @@ import (
 
 type (
 	searchType string
+	// SearchBestType is type of the best search result
+	SearchBestType string
 )
 
+// Types of the best search result
 const (
-	searchTypeArtist searchType = "artist"
-	searchTypeAlbum  searchType = "album"
-	searchTypeTrack  searchType = "track"
-	searchTypeAll    searchType = "all"
+	SearchBestArtist   SearchBestType = "artist"
+	SearchBestAlbum    SearchBestType = "album"
+	SearchBestTrack    SearchBestType = "track"
+	SearchBestPlaylist SearchBestType = "playlist"
+	SearchBestVideo    SearchBestType = "video"
+)
+
+const (
+	searchTypeArtist   searchType = "artist"
+	searchTypeAlbum    searchType = "album"
+	searchTypeTrack    searchType = "track"
+	searchTypePlaylist searchType = "playlist"
+	searchTypeVideo    searchType = "video"
+	searchTypeAll      searchType = "all"
 )
 
 type (
@@ -34,81 +49,22 @@ type (
 		InvocationInfo InvocationInfo `json:"invocationInfo"`
 		Error          Error          `json:"error"`
 		Result         struct {
-			MisspellCorrected bool   `json:"misspellCorrected"`
-			Nocorrect         bool   `json:"nocorrect"`
-			SearchRequestID   string `json:"searchRequestId"`
-			Text              string `json:"text"`
-			MisspellResult    string `json:"misspellResult"`
-			MisspellOriginal  string `json:"misspellOriginal"`
-			Best              struct {
-				Type   string       `json:"type"`
-				Result SearchResult `json:"result"`
-			} `json:"best"`
-			Tracks struct {
-				Total   int `json:"total"`
-				PerPage int `json:"perPage"`
-				Results []struct {
-					ID             int      `json:"id"`
-					DurationMs     int      `json:"durationMs"`
-					Available      bool     `json:"available"`
-					AvailableAsRbt bool     `json:"availableAsRbt"`
-					Explicit       bool     `json:"explicit"`
-					StorageDir     string   `json:"storageDir"`
-					Title          string   `json:"title"`
-					Version        string   `json:"version,omitempty"`
-					Regions        []string `json:"regions"`
-					Albums         []struct {
-						ID                  int           `json:"id"`
-						StorageDir          string        `json:"storageDir"`
-						OriginalReleaseYear int           `json:"originalReleaseYear"`
-						Year                int           `json:"year"`
-						Title               string        `json:"title"`
-						Artists             []interface{} `json:"artists"`
-						CoverURI            string        `json:"coverUri"`
-						TrackCount          int           `json:"trackCount"`
-						Genre               string        `json:"genre"`
-						Available           bool          `json:"available"`
-						TrackPosition       struct {
-							Volume int `json:"volume"`
-							Index  int `json:"index"`
-						} `json:"trackPosition"`
-					} `json:"albums"`
-					Artists []struct {
-						ID    int `json:"id"`
-						Cover struct {
-							Type   string `json:"type"`
-							Prefix string `json:"prefix"`
-							URI    string `json:"uri"`
-						} `json:"cover"`
-						Composer   bool          `json:"composer"`
-						Various    bool          `json:"various"`
-						Name       string        `json:"name"`
-						Decomposed []interface{} `json:"decomposed"`
-					} `json:"artists"`
-				} `json:"results"`
+			MisspellCorrected bool       `json:"misspellCorrected"`
+			Nocorrect         bool       `json:"nocorrect"`
+			SearchRequestID   string     `json:"searchRequestId"`
+			Text              string     `json:"text"`
+			MisspellResult    string     `json:"misspellResult"`
+			MisspellOriginal  string     `json:"misspellOriginal"`
+			Best              SearchBest `json:"best"`
+			Tracks            struct {
+				Total   int           `json:"total"`
+				PerPage int           `json:"perPage"`
+				Results []SearchTrack `json:"results"`
 			} `json:"tracks"`
 			Playlists struct {
-				Total   int `json:"total"`
-				PerPage int `json:"perPage"`
-				Results []struct {
-					UID        int    `json:"uid"`
-					Kind       int    `json:"kind"`
-					TrackCount int    `json:"trackCount"`
-					Title      string `json:"title"`
-					Owner      struct {
-						UID      int    `json:"uid"`
-						Login    string `json:"login"`
-						Name     string `json:"name"`
-						Verified bool   `json:"verified"`
-					} `json:"owner"`
-					Cover struct {
-						Type     string   `json:"type"`
-						ItemsURI []string `json:"itemsUri"`
-						Custom   bool     `json:"custom"`
-					} `json:"cover"`
-					Tags    []interface{} `json:"tags"`
-					Regions []string      `json:"regions"`
-				} `json:"results"`
+				Total   int              `json:"total"`
+				PerPage int              `json:"perPage"`
+				Results []SearchPlaylist `json:"results"`
 			} `json:"playlists"`
 			Artists struct {
 				Total   int            `json:"total"`
@@ -116,49 +72,139 @@ type (
 				Results []SearchResult `json:"results"`
 			} `json:"artists"`
 			Videos struct {
-				Total   int `json:"total"`
-				PerPage int `json:"perPage"`
-				Results []struct {
-					YoutubeURL              string   `json:"youtubeUrl"`
-					ThumbnailURL            string   `json:"thumbnailUrl"`
-					Title                   string   `json:"title"`
-					Duration                int      `json:"duration"`
-					Text                    string   `json:"text"`
-					HTMLAutoPlayVideoPlayer string   `json:"htmlAutoPlayVideoPlayer"`
-					Regions                 []string `json:"regions"`
-				} `json:"results"`
+				Total   int           `json:"total"`
+				PerPage int           `json:"perPage"`
+				Results []SearchVideo `json:"results"`
 			} `json:"videos"`
 			Albums struct {
-				Total   int `json:"total"`
-				PerPage int `json:"perPage"`
-				Results []struct {
-					ID                  int    `json:"id"`
-					StorageDir          string `json:"storageDir"`
-					OriginalReleaseYear int    `json:"originalReleaseYear"`
-					Year                int    `json:"year"`
-					Title               string `json:"title"`
-					Artists             []struct {
-						ID    int `json:"id"`
-						Cover struct {
-							Type   string `json:"type"`
-							Prefix string `json:"prefix"`
-							URI    string `json:"uri"`
-						} `json:"cover"`
-						Composer   bool          `json:"composer"`
-						Various    bool          `json:"various"`
-						Name       string        `json:"name"`
-						Decomposed []interface{} `json:"decomposed"`
-					} `json:"artists"`
-					CoverURI   string   `json:"coverUri"`
-					TrackCount int      `json:"trackCount"`
-					Genre      string   `json:"genre"`
-					Available  bool     `json:"available"`
-					Regions    []string `json:"regions"`
-				} `json:"results"`
+				Total   int           `json:"total"`
+				PerPage int           `json:"perPage"`
+				Results []SearchAlbum `json:"results"`
 			} `json:"albums"`
 		} `json:"result"`
 	}
 
+	// SearchBest is the best search result that is artist, album, track,
+	// playlist or video. Type tells which of them is set.
+	SearchBest struct {
+		Type SearchBestType `json:"type"`
+		// Text is query the result is found by, it's set in suggestions
+		Text string `json:"text,omitempty"`
+
+		Artist   *SearchResult   `json:"-"`
+		Album    *SearchAlbum    `json:"-"`
+		Track    *SearchTrack    `json:"-"`
+		Playlist *SearchPlaylist `json:"-"`
+		Video    *SearchVideo    `json:"-"`
+	}
+	// SearchSuggestResp describes search suggestions response
+	SearchSuggestResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			// Best is result matching the beginning of query, it's nil if
+			// nothing matches
+			Best        *SearchBest `json:"best"`
+			Suggestions []string    `json:"suggestions"`
+		} `json:"result"`
+	}
+
+	// SearchTrack is a track found by search
+	SearchTrack struct {
+		ID             int      `json:"id"`
+		DurationMs     int      `json:"durationMs"`
+		Available      bool     `json:"available"`
+		AvailableAsRbt bool     `json:"availableAsRbt"`
+		Explicit       bool     `json:"explicit"`
+		StorageDir     string   `json:"storageDir"`
+		Title          string   `json:"title"`
+		Version        string   `json:"version,omitempty"`
+		Regions        []string `json:"regions"`
+		Albums         []struct {
+			ID                  int           `json:"id"`
+			StorageDir          string        `json:"storageDir"`
+			OriginalReleaseYear int           `json:"originalReleaseYear"`
+			Year                int           `json:"year"`
+			Title               string        `json:"title"`
+			Artists             []interface{} `json:"artists"`
+			CoverURI            string        `json:"coverUri"`
+			TrackCount          int           `json:"trackCount"`
+			Genre               string        `json:"genre"`
+			Available           bool          `json:"available"`
+			TrackPosition       struct {
+				Volume int `json:"volume"`
+				Index  int `json:"index"`
+			} `json:"trackPosition"`
+		} `json:"albums"`
+		Artists []struct {
+			ID    int `json:"id"`
+			Cover struct {
+				Type   string `json:"type"`
+				Prefix string `json:"prefix"`
+				URI    string `json:"uri"`
+			} `json:"cover"`
+			Composer   bool          `json:"composer"`
+			Various    bool          `json:"various"`
+			Name       string        `json:"name"`
+			Decomposed []interface{} `json:"decomposed"`
+		} `json:"artists"`
+	}
+	// SearchPlaylist is a playlist found by search
+	SearchPlaylist struct {
+		UID        int    `json:"uid"`
+		Kind       int    `json:"kind"`
+		TrackCount int    `json:"trackCount"`
+		Title      string `json:"title"`
+		Owner      struct {
+			UID      int    `json:"uid"`
+			Login    string `json:"login"`
+			Name     string `json:"name"`
+			Verified bool   `json:"verified"`
+		} `json:"owner"`
+		Cover struct {
+			Type     string   `json:"type"`
+			ItemsURI []string `json:"itemsUri"`
+			Custom   bool     `json:"custom"`
+		} `json:"cover"`
+		Tags    []interface{} `json:"tags"`
+		Regions []string      `json:"regions"`
+	}
+	// SearchVideo is a video found by search
+	SearchVideo struct {
+		YoutubeURL              string   `json:"youtubeUrl"`
+		ThumbnailURL            string   `json:"thumbnailUrl"`
+		Title                   string   `json:"title"`
+		Duration                int      `json:"duration"`
+		Text                    string   `json:"text"`
+		HTMLAutoPlayVideoPlayer string   `json:"htmlAutoPlayVideoPlayer"`
+		Regions                 []string `json:"regions"`
+	}
+	// SearchAlbum is an album found by search
+	SearchAlbum struct {
+		ID                  int    `json:"id"`
+		StorageDir          string `json:"storageDir"`
+		OriginalReleaseYear int    `json:"originalReleaseYear"`
+		Year                int    `json:"year"`
+		Title               string `json:"title"`
+		Artists             []struct {
+			ID    int `json:"id"`
+			Cover struct {
+				Type   string `json:"type"`
+				Prefix string `json:"prefix"`
+				URI    string `json:"uri"`
+			} `json:"cover"`
+			Composer   bool          `json:"composer"`
+			Various    bool          `json:"various"`
+			Name       string        `json:"name"`
+			Decomposed []interface{} `json:"decomposed"`
+		} `json:"artists"`
+		CoverURI   string   `json:"coverUri"`
+		TrackCount int      `json:"trackCount"`
+		Genre      string   `json:"genre"`
+		Available  bool     `json:"available"`
+		Regions    []string `json:"regions"`
+	}
+
 	// SearchResult search result json
 	SearchResult struct {
 		ID               int      `json:"id"`
@@ -207,6 +253,45 @@ type (
 	}
 )
 
+// UnmarshalJSON decodes result into the field matching its type
+func (b *SearchBest) UnmarshalJSON(data []byte) error {
+	type best SearchBest
+	tagged := struct {
+		*best
+		Result json.RawMessage `json:"result"`
+	}{best: (*best)(b)}
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	if len(tagged.Result) == 0 || string(tagged.Result) == "null" {
+		return nil
+	}
+
+	return decodeTagged(tagged.Result, b.Type, map[SearchBestType]any{
+		SearchBestArtist:   &b.Artist,
+		SearchBestAlbum:    &b.Album,
+		SearchBestTrack:    &b.Track,
+		SearchBestPlaylist: &b.Playlist,
+		SearchBestVideo:    &b.Video,
+	})
+}
+
+// Suggest returns suggestions of queries and the best result
+// for the beginning of query typed so far
+func (s *SearchService) Suggest(ctx context.Context, part string) (*SearchSuggestResp, *http.Response, error) {
+	queryParams := url.Values{}
+	queryParams.Set("part", part)
+
+	req, err := s.client.NewRequest(http.MethodGet, "search/suggest?"+queryParams.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	suggestions := new(SearchSuggestResp)
+	resp, err := s.client.Do(ctx, req, suggestions)
+	return suggestions, resp, err
+}
+
 // Artists searches artists by query
 func (s *SearchService) Artists(
 	ctx context.Context,
@@ -243,6 +328,137 @@ func (s *SearchService) All(
 	return s.search(ctx, searchTypeAll, query, opts)
 }
 
+type (
+	// SearchIterOptions defines options of search iterators
+	SearchIterOptions struct {
+		NoCorrect bool
+		// MaxResults caps number of yielded results. Zero means no cap.
+		MaxResults int
+	}
+)
+
+// IterTracks returns iterator over all tracks found by query.
+// Pages are requested lazily until total is exhausted.
+func (s *SearchService) IterTracks(
+	ctx context.Context,
+	query string,
+	opts *SearchIterOptions,
+) iter.Seq2[SearchTrack, error] {
+	return searchSeq(ctx, s, searchTypeTrack, query, opts,
+		func(resp *SearchResp) ([]SearchTrack, int, int) {
+			tracks := resp.Result.Tracks
+			return tracks.Results, tracks.Total, tracks.PerPage
+		})
+}
+
+// IterAlbums returns iterator over all albums found by query.
+// Pages are requested lazily until total is exhausted.
+func (s *SearchService) IterAlbums(
+	ctx context.Context,
+	query string,
+	opts *SearchIterOptions,
+) iter.Seq2[SearchAlbum, error] {
+	return searchSeq(ctx, s, searchTypeAlbum, query, opts,
+		func(resp *SearchResp) ([]SearchAlbum, int, int) {
+			albums := resp.Result.Albums
+			return albums.Results, albums.Total, albums.PerPage
+		})
+}
+
+// IterArtists returns iterator over all artists found by query.
+// Pages are requested lazily until total is exhausted.
+func (s *SearchService) IterArtists(
+	ctx context.Context,
+	query string,
+	opts *SearchIterOptions,
+) iter.Seq2[SearchResult, error] {
+	return searchSeq(ctx, s, searchTypeArtist, query, opts,
+		func(resp *SearchResp) ([]SearchResult, int, int) {
+			artists := resp.Result.Artists
+			return artists.Results, artists.Total, artists.PerPage
+		})
+}
+
+// IterPlaylists returns iterator over all playlists found by query.
+// Pages are requested lazily until total is exhausted.
+func (s *SearchService) IterPlaylists(
+	ctx context.Context,
+	query string,
+	opts *SearchIterOptions,
+) iter.Seq2[SearchPlaylist, error] {
+	return searchSeq(ctx, s, searchTypePlaylist, query, opts,
+		func(resp *SearchResp) ([]SearchPlaylist, int, int) {
+			playlists := resp.Result.Playlists
+			return playlists.Results, playlists.Total, playlists.PerPage
+		})
+}
+
+// IterVideos returns iterator over all videos found by query.
+// Pages are requested lazily until total is exhausted.
+func (s *SearchService) IterVideos(
+	ctx context.Context,
+	query string,
+	opts *SearchIterOptions,
+) iter.Seq2[SearchVideo, error] {
+	return searchSeq(ctx, s, searchTypeVideo, query, opts,
+		func(resp *SearchResp) ([]SearchVideo, int, int) {
+			videos := resp.Result.Videos
+			return videos.Results, videos.Total, videos.PerPage
+		})
+}
+
+// searchSeq returns iterator walking pages of search by searchTyp.
+// section returns results, total and number of results per page of
+// the section of response.
+func searchSeq[T any](
+	ctx context.Context,
+	s *SearchService,
+	searchTyp searchType,
+	query string,
+	opts *SearchIterOptions,
+	section func(resp *SearchResp) ([]T, int, int),
+) iter.Seq2[T, error] {
+	if opts == nil {
+		opts = &SearchIterOptions{}
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		yielded := 0
+
+		for page := 0; ; page++ {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			resp, _, err := s.search(ctx, searchTyp, query, &SearchOptions{
+				Page:      page,
+				NoCorrect: opts.NoCorrect,
+			})
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			results, total, perPage := section(resp)
+			for _, result := range results {
+				if !yield(result, nil) {
+					return
+				}
+				yielded++
+				if opts.MaxResults > 0 && yielded >= opts.MaxResults {
+					return
+				}
+			}
+
+			if len(results) == 0 || perPage <= 0 || (page+1)*perPage >= total {
+				return
+			}
+		}
+	}
+}
+
 func (s *SearchService) search(
 	ctx context.Context,
 	searchTyp searchType,
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -60,3 +61,178 @@ func testSearch(t *testing.T, searchType string, searchFunc func(
 	assert.NoError(t, err)
 	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
 }
+
+func TestSearchService_IterTracks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	pages := 0
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "track", r.URL.Query().Get("type"))
+		assert.Equal(t, strconv.Itoa(pages), r.URL.Query().Get("page"))
+		pages++
+
+		resp := &SearchResp{}
+		resp.Result.Tracks.Total = 5
+		resp.Result.Tracks.PerPage = 2
+		for i := 0; i < 2 && (pages-1)*2+i < 5; i++ {
+			resp.Result.Tracks.Results = append(resp.Result.Tracks.Results, SearchTrack{ID: (pages-1)*2 + i})
+		}
+		b, err := json.Marshal(resp)
+		assert.NoError(t, err)
+		fmt.Fprint(w, string(b))
+	})
+
+	var ids []int
+	for track, err := range client.Search().IterTracks(context.Background(), "blah", nil) {
+		assert.NoError(t, err)
+		ids = append(ids, track.ID)
+	}
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, ids)
+	assert.Equal(t, 3, pages)
+}
+
+func TestSearchService_IterArtistsMaxResults(t *testing.T) {
+	setup()
+	defer teardown()
+
+	pages := 0
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "artist", r.URL.Query().Get("type"))
+		pages++
+		fmt.Fprint(w, `{"result":{"artists":{"total":100,"perPage":2,"results":[{"id":1},{"id":2}]}}}`)
+	})
+
+	count := 0
+	for _, err := range client.Search().IterArtists(
+		context.Background(),
+		"blah",
+		&SearchIterOptions{MaxResults: 3},
+	) {
+		assert.NoError(t, err)
+		count++
+	}
+
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 2, pages)
+}
+
+func TestSearchService_IterArtistsMaxResultsOnPageEnd(t *testing.T) {
+	setup()
+	defer teardown()
+
+	pages := 0
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		fmt.Fprint(w, `{"result":{"artists":{"total":100,"perPage":2,"results":[{"id":1},{"id":2}]}}}`)
+	})
+
+	count := 0
+	for _, err := range client.Search().IterArtists(
+		context.Background(),
+		"blah",
+		&SearchIterOptions{MaxResults: 2},
+	) {
+		assert.NoError(t, err)
+		count++
+	}
+
+	// The next page isn't requested once the cap is reached
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 1, pages)
+}
+
+func TestSearchService_IterPlaylistsError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"result":{"playlists":{"total":4,"perPage":2,"results":[{"kind":1},{"kind":2}]}}}`)
+	})
+
+	var kinds []int
+	var errs []error
+	for playlist, err := range client.Search().IterPlaylists(context.Background(), "blah", nil) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		kinds = append(kinds, playlist.Kind)
+	}
+
+	assert.Equal(t, []int{1, 2}, kinds)
+	if assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs[0], ErrRateLimited)
+	}
+}
+
+func TestSearchService_IterVideosCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, err := range client.Search().IterVideos(ctx, "blah", nil) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestSearchService_Suggest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/search/suggest", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "OAuth "+accessToken, r.Header.Get("Authorization"))
+		assert.Equal(t, "muse upr", r.URL.Query().Get("part"))
+
+		fmt.Fprint(w, `{"result":{
+			"best":{"type":"track","text":"muse uprising","result":{"id":1,"title":"Uprising"}},
+			"suggestions":["muse uprising","muse"]
+		}}`)
+	})
+
+	result, _, err := client.Search().Suggest(context.Background(), "muse upr")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"muse uprising", "muse"}, result.Result.Suggestions)
+	if assert.NotNil(t, result.Result.Best) && assert.NotNil(t, result.Result.Best.Track) {
+		assert.Equal(t, "Uprising", result.Result.Best.Track.Title)
+		assert.Equal(t, "muse uprising", result.Result.Best.Text)
+	}
+}
+
+func TestSearchBest_UnmarshalJSON(t *testing.T) {
+	var resp SearchResp
+	err := json.Unmarshal([]byte(`{"result":{"best":{"type":"album","result":{"id":10,"title":"The Resistance"}}}}`), &resp)
+	assert.NoError(t, err)
+	best := resp.Result.Best
+	if assert.NotNil(t, best.Album) {
+		assert.Equal(t, 10, best.Album.ID)
+	}
+	assert.Nil(t, best.Artist)
+	assert.Nil(t, best.Track)
+
+	err = json.Unmarshal([]byte(`{"type":"artist","result":{"id":7,"name":"Muse"}}`), &best)
+	assert.NoError(t, err)
+	if assert.NotNil(t, best.Artist) {
+		assert.Equal(t, "Muse", best.Artist.Name)
+	}
+
+	var unknown SearchBest
+	err = json.Unmarshal([]byte(`{"type":"podcast","result":{"id":1}}`), &unknown)
+	assert.NoError(t, err)
+	assert.Equal(t, SearchBestType("podcast"), unknown.Type)
+	assert.Nil(t, unknown.Artist)
+	assert.Nil(t, unknown.Album)
+
+	var empty SearchResp
+	assert.NoError(t, json.Unmarshal([]byte(`{"result":{}}`), &empty))
+	assert.Nil(t, empty.Result.Best.Artist)
+}
@@ -0,0 +1,225 @@
+package yamusic
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName is a name of tracer and meter of the client
+const instrumentationName = "awesome/yamusic"
+
+type (
+	// TelemetryOptions configures OpenTelemetry tracing and metrics.
+	// Providers are set up by the application, e.g. with OTLP exporters
+	// to a local collector or with stdout exporters.
+	TelemetryOptions struct {
+		// TracerProvider creates tracer of the client.
+		// Default is the global otel tracer provider.
+		TracerProvider trace.TracerProvider
+		// MeterProvider creates meter of the client.
+		// Default is the global otel meter provider.
+		MeterProvider metric.MeterProvider
+	}
+	// telemetry records spans and metrics of API requests
+	telemetry struct {
+		tracer     trace.Tracer
+		requests   metric.Int64Counter
+		duration   metric.Float64Histogram
+		errors     metric.Int64Counter
+		downloaded metric.Int64Counter
+	}
+)
+
+// Telemetry enables OpenTelemetry spans and metrics of every API request
+// and track download. If opts is nil, global otel providers are used.
+// Without this option telemetry is a no-op.
+func Telemetry(opts *TelemetryOptions) func(*Client) {
+	return func(c *Client) {
+		if opts == nil {
+			opts = &TelemetryOptions{}
+		}
+		tracerProvider := opts.TracerProvider
+		if tracerProvider == nil {
+			tracerProvider = otel.GetTracerProvider()
+		}
+		meterProvider := opts.MeterProvider
+		if meterProvider == nil {
+			meterProvider = otel.GetMeterProvider()
+		}
+		c.telemetry = newTelemetry(tracerProvider, meterProvider, c.logger)
+	}
+}
+
+// newNoopTelemetry returns telemetry recording nothing
+func newNoopTelemetry() *telemetry {
+	return newTelemetry(tracenoop.NewTracerProvider(), metricnoop.NewMeterProvider(), nil)
+}
+
+func newTelemetry(
+	tracerProvider trace.TracerProvider,
+	meterProvider metric.MeterProvider,
+	logger *slog.Logger,
+) *telemetry {
+	meter := meterProvider.Meter(instrumentationName)
+	t := &telemetry{tracer: tracerProvider.Tracer(instrumentationName)}
+
+	var err error
+	t.requests, err = meter.Int64Counter("yamusic.client.requests",
+		metric.WithDescription("Number of requests to Yandex.Music API"),
+		metric.WithUnit("{request}"))
+	errs := []error{err}
+	t.duration, err = meter.Float64Histogram("yamusic.client.request.duration",
+		metric.WithDescription("Duration of requests to Yandex.Music API"),
+		metric.WithUnit("s"))
+	errs = append(errs, err)
+	t.errors, err = meter.Int64Counter("yamusic.client.errors",
+		metric.WithDescription("Number of failed requests to Yandex.Music API"),
+		metric.WithUnit("{request}"))
+	errs = append(errs, err)
+	t.downloaded, err = meter.Int64Counter("yamusic.client.downloaded",
+		metric.WithDescription("Size of downloaded tracks"),
+		metric.WithUnit("By"))
+	errs = append(errs, err)
+
+	// Instruments are still usable if the meter reported an error
+	if err := errors.Join(errs...); err != nil && logger != nil {
+		logger.Error("cannot create metric instruments", "error", err)
+	}
+
+	return t
+}
+
+// startRequest starts span of req. Returned function ends the span and
+// records metrics of the request.
+func (t *telemetry) startRequest(
+	ctx context.Context,
+	req *http.Request,
+) (context.Context, func(resp *http.Response, err error)) {
+	endpoint := endpointOf(req.URL.Path)
+	ctx, span := t.tracer.Start(ctx, req.Method+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("yamusic.endpoint", endpoint),
+			attribute.String("server.address", req.URL.Host),
+		),
+	)
+	start := time.Now()
+
+	return ctx, func(resp *http.Response, err error) {
+		attrs := []attribute.KeyValue{
+			attribute.String("http.request.method", req.Method),
+			attribute.String("yamusic.endpoint", endpoint),
+		}
+		if resp != nil {
+			attrs = append(attrs, attribute.Int("http.response.status_code", resp.StatusCode))
+		}
+		if err != nil {
+			class := errorClass(err)
+			attrs = append(attrs, attribute.String("error.type", class))
+			t.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, class)
+		}
+
+		t.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+		t.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+		span.SetAttributes(attrs...)
+		span.End()
+	}
+}
+
+// addDownloaded records size of downloaded track
+func (t *telemetry) addDownloaded(ctx context.Context, n int64) {
+	t.downloaded.Add(ctx, n)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("yamusic.downloaded", n))
+}
+
+// endpointRoutes are beginnings of API paths with their variable segments
+// in braces. Routes with literal segments go before ones matching them.
+var endpointRoutes = [][]string{
+	{"users", "{uid}", "playlists", "list"},
+	{"users", "{uid}", "playlists", "create"},
+	{"users", "{uid}", "playlists", "{kind}"},
+	{"users", "{uid}"},
+	{"tracks", "{id}"},
+	{"albums", "{id}"},
+	{"artists", "{id}"},
+	{"rotor", "station", "{station}"},
+	{"queues", "{id}"},
+	{"landing3", "chart", "{chart}"},
+}
+
+// endpointOf returns path with ids, logins and other variable segments
+// replaced by route, e.g. /users/{uid}/playlists/{kind}, so endpoints
+// have low cardinality in metrics. Paths of storage files are replaced
+// entirely.
+func endpointOf(path string) string {
+	if strings.HasPrefix(path, "/get-mp3/") {
+		return "/get-mp3/{path}"
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for _, route := range endpointRoutes {
+		if matchRoute(route, segments) {
+			copy(segments, route)
+			break
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// matchRoute reports whether path segments begin with route
+func matchRoute(route, segments []string) bool {
+	if len(segments) < len(route) {
+		return false
+	}
+	for i, segment := range route {
+		if strings.HasPrefix(segment, "{") {
+			if segments[i] == "" {
+				return false
+			}
+		} else if segments[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// errorClass returns short class of err to use in metrics
+func errorClass(err error) string {
+	var apiErr *APIError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrRevisionConflict):
+		return "revision_conflict"
+	case errors.As(err, &apiErr):
+		return "api"
+	case errors.As(err, &netErr):
+		return "network"
+	}
+	return "other"
+}
@@ -0,0 +1,125 @@
+package yamusic
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTelemetry() (*tracetest.SpanRecorder, *sdkmetric.ManualReader, func(*Client)) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	return spans, reader, Telemetry(&TelemetryOptions{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+}
+
+// collectSums returns sums of counter by error.type attribute
+func collectSums(t *testing.T, reader *sdkmetric.ManualReader, name string) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+
+	sums := map[string]int64{}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				class, _ := point.Attributes.Value("error.type")
+				sums[class.AsString()] += point.Value
+			}
+		}
+	}
+	return sums
+}
+
+func TestTelemetry_Do(t *testing.T) {
+	spans, reader, telemetry := setupTelemetry()
+	setup(telemetry)
+	defer teardown()
+
+	mux.HandleFunc("/tracks/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[]}`))
+	})
+	mux.HandleFunc("/tracks/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, _, err := client.Tracks().GetOne(context.Background(), 1)
+	assert.NoError(t, err)
+	_, _, err = client.Tracks().GetOne(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ended := spans.Ended()
+	if assert.Len(t, ended, 2) {
+		assert.Equal(t, "GET /tracks/{id}", ended[0].Name())
+		assert.Equal(t, codes.Unset, ended[0].Status().Code)
+		assert.Contains(t, ended[0].Attributes(), attribute.Int("http.response.status_code", 200))
+		assert.Equal(t, codes.Error, ended[1].Status().Code)
+		assert.Contains(t, ended[1].Attributes(), attribute.String("error.type", "not_found"))
+	}
+
+	assert.Equal(t, map[string]int64{"": 1, "not_found": 1}, collectSums(t, reader, "yamusic.client.requests"))
+	assert.Equal(t, map[string]int64{"not_found": 1}, collectSums(t, reader, "yamusic.client.errors"))
+}
+
+func TestTelemetry_Download(t *testing.T) {
+	spans, reader, telemetry := setupTelemetry()
+	server, client := setupDownload(t, []int{1}, telemetry)
+	defer server.Close()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(dir+"/tracks", os.ModePerm))
+
+	err := client.Tracks().Download(context.Background(), Track{ID: "1", Title: "One"}, dir)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, span := range spans.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Contains(t, names, "TracksService.Download")
+	assert.Contains(t, names, "GET /get-mp3/{path}")
+
+	assert.Equal(t, map[string]int64{"": int64(len("mp3 of 1"))}, collectSums(t, reader, "yamusic.client.downloaded"))
+}
+
+func TestTelemetry_NoopByDefault(t *testing.T) {
+	c := NewClient()
+	ctx, finish := c.telemetry.startRequest(context.Background(), &http.Request{
+		Method: http.MethodGet,
+		URL:    c.baseURL,
+	})
+	finish(nil, context.Canceled)
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestEndpointOf(t *testing.T) {
+	for path, want := range map[string]string{
+		"/users/42/playlists/1003":                "/users/{uid}/playlists/{kind}",
+		"/users/login/playlists/1003/cover/clear": "/users/{uid}/playlists/{kind}/cover/clear",
+		"/users/42/playlists/list":                "/users/{uid}/playlists/list",
+		"/users/login/likes/tracks/add-multiple":  "/users/{uid}/likes/tracks/add-multiple",
+		"/tracks/20345844:123/download-info":      "/tracks/{id}/download-info",
+		"/tracks":                                 "/tracks",
+		"/rotor/station/user:onyourwave/tracks":   "/rotor/station/{station}/tracks",
+		"/queues/5f0c3a/update-position":          "/queues/{id}/update-position",
+		"/landing3":                               "/landing3",
+		"/get-mp3/abc/0005/track/1":               "/get-mp3/{path}",
+		"/genres":                                 "/genres",
+	} {
+		assert.Equal(t, want, endpointOf(path), path)
+	}
+}
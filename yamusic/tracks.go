@@ -5,14 +5,19 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type (
@@ -54,6 +59,15 @@ type (
 			} `json:"lyrics"`
 		} `json:"result"`
 	}
+	// SimilarTracksResp describes get similar tracks response
+	SimilarTracksResp struct {
+		InvocationInfo InvocationInfo `json:"invocationInfo"`
+		Error          Error          `json:"error"`
+		Result         struct {
+			Track         Track   `json:"track"`
+			SimilarTracks []Track `json:"similarTracks"`
+		} `json:"result"`
+	}
 	// Response of track/%d/download_info
 	DownloadInfoResp struct {
 		InvocationInfo InvocationInfo `json:"invocationInfo"`
@@ -79,6 +93,62 @@ type (
 	}
 )
 
+// GetAllOptions defines options of TracksService.GetAll
+type GetAllOptions struct {
+	// BatchSize is max number of track IDs in one request. Default is 200.
+	BatchSize int
+	// Concurrency is max number of concurrent requests. Default is 4.
+	Concurrency int
+}
+
+// GetAllResult is a result of TracksService.GetAll
+type GetAllResult struct {
+	// Tracks are found tracks in order of requested IDs
+	Tracks []Track
+	// Missing are requested IDs that the API didn't return
+	Missing []string
+}
+
+// DownloadResult is a summary of downloading several tracks
+type DownloadResult struct {
+	// Downloaded is number of downloaded tracks
+	Downloaded int
+	// Skipped is number of tracks that were already on fs
+	Skipped int
+	// Failed are errors of tracks that weren't downloaded
+	Failed []*DownloadError
+}
+
+// DownloadError is an error of downloading a track
+type DownloadError struct {
+	Track Track
+	Err   error
+}
+
+func (e *DownloadError) Error() string {
+	return fmt.Sprintf("download track %s %q: %v", e.Track.ID, e.Track.Title, e.Err)
+}
+
+func (e *DownloadError) Unwrap() error { return e.Err }
+
+// add adds numbers and errors of other result
+func (r *DownloadResult) add(other *DownloadResult) {
+	if other == nil {
+		return
+	}
+	r.Downloaded += other.Downloaded
+	r.Skipped += other.Skipped
+	r.Failed = append(r.Failed, other.Failed...)
+}
+
+func (r *DownloadResult) errors() []error {
+	errs := make([]error, 0, len(r.Failed))
+	for _, err := range r.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
 type TrackError string
 
 func (te TrackError) Error() string { return string(te) }
@@ -103,12 +173,107 @@ func (t *TracksService) GetOne(ctx context.Context, id int) (*TrackResp, *http.R
 	return track, resp, err
 }
 
-// Get returns track by its ID
-func (t *TracksService) GetAll(ctx context.Context, track_ids []string) (*TrackResp, *http.Response, error) {
+// GetAll returns tracks by their IDs. IDs are split into batches that are
+// requested concurrently. Found tracks are returned in order of ids, IDs
+// the API didn't return are reported in GetAllResult.Missing. If opts is
+// nil, default options are used.
+func (t *TracksService) GetAll(
+	ctx context.Context,
+	ids []string,
+	opts *GetAllOptions,
+) (*GetAllResult, error) {
+	if opts == nil {
+		opts = &GetAllOptions{}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	// Duplicates are requested and returned once
+	var unique []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	var batches [][]string
+	for start := 0; start < len(unique); start += batchSize {
+		batches = append(batches, unique[start:min(start+batchSize, len(unique))])
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([][]Track, len(batches))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			tracks, _, err := t.getBatch(ctx, batch)
+			if err != nil {
+				t.client.logger.Error("cannot get tracks",
+					"batch", i, "count", len(batch), "error", err)
+				cancel(err)
+				return
+			}
+			results[i] = tracks.Result
+		}()
+	}
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]Track, len(unique))
+	for _, tracks := range results {
+		for _, track := range tracks {
+			found[track.ID] = track
+		}
+	}
+
+	result := &GetAllResult{Tracks: make([]Track, 0, len(unique))}
+	for _, id := range unique {
+		// Track ID may be given with album ID as trackID:albumID
+		trackID, _, _ := strings.Cut(id, ":")
+		if track, ok := found[trackID]; ok {
+			result.Tracks = append(result.Tracks, track)
+			continue
+		}
+		result.Missing = append(result.Missing, id)
+	}
+
+	return result, nil
+}
+
+// getBatch returns tracks by their IDs in one request
+func (t *TracksService) getBatch(ctx context.Context, ids []string) (*TrackResp, *http.Response, error) {
 	uri := "tracks"
 
 	form := url.Values{}
-	form.Set("track-ids", strings.Join(track_ids[:], ","))
+	form.Set("track-ids", strings.Join(ids, ","))
 	form.Set("with-positions", "false")
 
 	req, err := t.client.NewRequest(http.MethodPost, uri, form)
@@ -119,7 +284,7 @@ func (t *TracksService) GetAll(ctx context.Context, track_ids []string) (*TrackR
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	tracks := new(TrackResp)
-	resp, err := t.client.Do(ctx, req, tracks)
+	resp, err := t.client.Do(withRetrySafe(ctx), req, tracks)
 
 	return tracks, resp, err
 }
@@ -137,6 +302,19 @@ func (t *TracksService) GetLike(ctx context.Context) (*LikeTracksResp, *http.Res
 	return like_tracks, resp, err
 }
 
+// GetSimilar returns tracks similar to track by id
+func (t *TracksService) GetSimilar(ctx context.Context, id string) (*SimilarTracksResp, *http.Response, error) {
+	uri := fmt.Sprintf("tracks/%v/similar", id)
+	req, err := t.client.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	similar := new(SimilarTracksResp)
+	resp, err := t.client.Do(ctx, req, similar)
+	return similar, resp, err
+}
+
 // List returns playlists of the user
 func (t *TracksService) GetSupplement(ctx context.Context, id string) (*Supplement, *http.Response, error) {
 	uri := fmt.Sprintf("tracks/%v/supplement", id)
@@ -216,74 +394,123 @@ func (t *TracksService) GetDownloadURL(ctx context.Context, id int) (string, err
 	return uri, nil
 }
 
-// Download track by DownloadURL by path on fs
-func (t *TracksService) DownloadAll(ctx context.Context, tracks []Track, path string) {
+// DownloadAll downloads tracks that are not on fs yet into path/tracks and
+// their lyrics like Download does. Failed tracks don't stop the download,
+// their errors are joined into the returned error. Download stops when ctx
+// is done.
+func (t *TracksService) DownloadAll(
+	ctx context.Context,
+	tracks []Track,
+	path string,
+) (*DownloadResult, error) {
+	result := new(DownloadResult)
+
+	for _, dir := range []string{path + "/tracks", path + "/lyrics"} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return result, err
+		}
+	}
+
 	/// Get list directory
 	entries, err := os.ReadDir(path + "/tracks")
 	if err != nil {
-		log.Fatal(err)
+		return result, err
 	}
 
-	var entry_name string
 	tracks_on_fs := map[string]bool{}
 
 	for _, entry := range entries {
-		entry_name = entry.Name()
-		entry_name = strings.ReplaceAll(entry_name, ".mp3", "")
-		logInfo.Println(entry_name)
+		// Synced lyrics are saved next to tracks
+		entry_name, ok := strings.CutSuffix(entry.Name(), ".mp3")
+		if !ok {
+			continue
+		}
+		t.client.logger.Debug("track on fs", "file", entry_name)
 		tracks_on_fs[entry_name] = true
 	}
 
-	logInfo.Printf("Already loaded tracks by path %s: %d", path, len(entries))
+	t.client.logger.Info("already loaded tracks", "path", path, "count", len(tracks_on_fs))
 	for _, track := range tracks {
+		if err := ctx.Err(); err != nil {
+			return result, errors.Join(append(result.errors(), err)...)
+		}
+
 		file_name := t.GetFileName(ctx, track)
-		if !tracks_on_fs[file_name] {
-			logInfo.Printf("%s", file_name)
-			t.Download(ctx, track, path)
+		if tracks_on_fs[file_name] {
+			result.Skipped++
+			continue
+		}
+
+		t.client.logger.Info("download track", "track_id", track.ID, "file", file_name)
+		if err := t.Download(ctx, track, path); err != nil {
+			t.client.logger.Error("cannot load track",
+				"track_id", track.ID, "file", file_name, "error", err)
+			result.Failed = append(result.Failed, &DownloadError{Track: track, Err: err})
+			continue
 		}
+		result.Downloaded++
 	}
+
+	return result, errors.Join(result.errors()...)
 }
 
-// Download track by DownloadURL by path on fs
-func (t *TracksService) Download(ctx context.Context, track Track, path string) {
+// Download downloads track into path/tracks. Its synced lyrics are saved
+// as .lrc next to the track, plain text lyrics are saved into path/lyrics.
+// Directories must exist. Partially downloaded file is removed on error.
+func (t *TracksService) Download(ctx context.Context, track Track, path string) (err error) {
+	ctx, span := t.client.telemetry.tracer.Start(ctx, "TracksService.Download",
+		trace.WithAttributes(attribute.String("yamusic.track_id", track.ID)))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, errorClass(err))
+		}
+		span.End()
+	}()
 
 	// load track mp3
 	file_name := path + "/tracks/" + t.GetFileName(ctx, track) + ".mp3"
 
-	track_id, _ := strconv.Atoi(track.ID)
-	uri, _ := t.GetDownloadURL(context.Background(), track_id)
-	logInfo.Println(uri)
-
-	output_file, _ := os.Create(file_name)
-	defer output_file.Close()
+	track_id, err := strconv.Atoi(track.ID)
+	if err != nil {
+		return err
+	}
+	uri, err := t.GetDownloadURL(ctx, track_id)
+	if err != nil {
+		return err
+	}
+	t.client.logger.Debug("track download url", "track_id", track.ID, "url", uri)
 
-	resp, err := http.Get(uri)
-	if err == nil {
-		defer resp.Body.Close()
-		io.Copy(output_file, resp.Body)
-	} else {
-		logInfo.Println(err)
-		logInfo.Println("Cannot load " + file_name)
+	if err := t.downloadFile(ctx, uri, file_name); err != nil {
+		return err
 	}
 
-	// load track lyrics txt
-	if track.LyricsAvailable {
-		file_name = path + "/lyrics/" + t.GetFileName(ctx, track) + ".txt"
-		supplement, _, _ := t.GetSupplement(ctx, track.ID)
-		// open input file
-		fi, err := os.Create(file_name)
-		if err != nil {
-			panic(err)
-		}
-		// close fi on exit and check for its returned error
-		defer func() {
-			if err := fi.Close(); err != nil {
-				panic(err)
-			}
-		}()
-		fmt.Fprintln(fi, supplement.Result.Lyrics.FullLyrics)
+	return t.downloadLyrics(ctx, track, file_name, path+"/lyrics")
+}
+
+// downloadFile downloads file from the storage by uri into path
+func (t *TracksService) downloadFile(ctx context.Context, uri, path string) error {
+	return t.getStorage(ctx, uri, func(r io.Reader) (int64, error) {
+		return writeFile(path, r)
+	})
+}
+
+// writeFile writes r into file by path removing the file if writing failed
+// and returns number of written bytes
+func writeFile(path string, r io.Reader) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
 	}
 
+	n, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return n, err
 }
 
 func (t *TracksService) GetFileName(ctx context.Context, track Track) string {
@@ -5,7 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"awesome/yamusic/recorder"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -30,7 +39,7 @@ func TestTracksSevice_Get(t *testing.T) {
 		},
 	)
 
-	result, _, err := client.Tracks().Get(
+	result, _, err := client.Tracks().GetOne(
 		context.Background(),
 		kind,
 	)
@@ -39,6 +48,101 @@ func TestTracksSevice_Get(t *testing.T) {
 	assert.Equal(t, want.InvocationInfo.ReqID, result.InvocationInfo.ReqID)
 }
 
+func TestTracksService_GetAll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	var batches []string
+	active, maxActive := 0, 0
+	mux.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		batches = append(batches, r.FormValue("track-ids"))
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+
+		// Tracks are returned in reverse order and "4" is unknown
+		var tracks []Track
+		for _, id := range strings.Split(r.FormValue("track-ids"), ",") {
+			id, _, _ = strings.Cut(id, ":")
+			if id != "4" {
+				tracks = append([]Track{{ID: id}}, tracks...)
+			}
+		}
+		b, err := json.Marshal(&TrackResp{Result: tracks})
+		assert.NoError(t, err)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		fmt.Fprint(w, string(b))
+	})
+
+	ids := []string{"1", "2:10", "3", "4", "5", "1", "6", "7"}
+	result, err := client.Tracks().GetAll(
+		context.Background(),
+		ids,
+		&GetAllOptions{BatchSize: 2, Concurrency: 2},
+	)
+
+	assert.NoError(t, err)
+	var got []string
+	for _, track := range result.Tracks {
+		got = append(got, track.ID)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "5", "6", "7"}, got)
+	assert.Equal(t, []string{"4"}, result.Missing)
+	assert.ElementsMatch(t, []string{"1,2:10", "3,4", "5,6", "7"}, batches)
+	assert.LessOrEqual(t, maxActive, 2)
+}
+
+func TestTracksService_GetAllError(t *testing.T) {
+	setup(Retry(&RetryOptions{MaxAttempts: 1}))
+	defer teardown()
+
+	mux.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		if r.FormValue("track-ids") == "3" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"result":[]}`)
+	})
+
+	result, err := client.Tracks().GetAll(
+		context.Background(),
+		[]string{"1", "2", "3"},
+		&GetAllOptions{BatchSize: 1},
+	)
+
+	assert.Nil(t, result)
+	var apiErr *APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	}
+}
+
+func TestTracksService_GetSimilar(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tracks/1/similar", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		fmt.Fprint(w, `{"result":{"track":{"id":"1"},"similarTracks":[{"id":"2"},{"id":"3"}]}}`)
+	})
+
+	similar, _, err := client.Tracks().GetSimilar(context.Background(), "1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", similar.Result.Track.ID)
+	assert.Len(t, similar.Result.SimilarTracks, 2)
+}
+
 func TestTracksSevice_GetDownloadInfoResp(t *testing.T) {
 	setup()
 	defer teardown()
@@ -118,3 +222,110 @@ func TestTracksSevice_GetDownloadInfo(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func TestTracksSevice_GetOneReplay(t *testing.T) {
+	rec, err := recorder.New("testdata/cassettes/tracks", recorder.ModeReplay, nil)
+	assert.NoError(t, err)
+	defer rec.Stop()
+
+	client := NewClient(HTTPClient(rec))
+
+	result, _, err := client.Tracks().GetOne(context.Background(), 20345844)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result.Result, 1) {
+		track := result.Result[0]
+		assert.Equal(t, "20345844", track.ID)
+		assert.Equal(t, "In Too Deep", track.Title)
+		assert.Equal(t, 207600, track.DurationMs)
+		assert.Equal(t, "Sum 41", track.Artists[0].Name)
+		assert.Equal(t, 2243491, track.Albums[0].ID)
+		assert.Equal(t, 4, track.Albums[0].TrackPosition.Index)
+		assert.True(t, track.LyricsInfo.HasAvailableSyncLyrics)
+	}
+
+	_, _, err = client.Tracks().GetOne(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// setupDownload sets up TLS test server serving download info and storage
+// of tracks by ids. Other tracks are not found.
+func setupDownload(
+	t *testing.T,
+	ids []int,
+	options ...func(*Client),
+) (*httptest.Server, *Client) {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+
+	for _, id := range ids {
+		mux.HandleFunc(
+			fmt.Sprintf("/tracks/%d/download-info", id),
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"result":[{"codec":"mp3","downloadInfoUrl":"%s/download-info/%d"}]}`, server.URL, id)
+			},
+		)
+		mux.HandleFunc(
+			fmt.Sprintf("/download-info/%d", id),
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(
+					w,
+					`<download-info><host>%s</host><path>/track/%d</path><ts>ts</ts><s>s</s></download-info>`,
+					strings.TrimPrefix(server.URL, "https://"), id,
+				)
+			},
+		)
+	}
+	mux.HandleFunc("/get-mp3/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "mp3 of "+path.Base(r.URL.Path))
+	})
+
+	u, _ := url.Parse(server.URL + "/")
+	options = append([]func(*Client){BaseURL(u), HTTPClient(server.Client())}, options...)
+	return server, NewClient(options...)
+}
+
+func TestTracksService_DownloadAll(t *testing.T) {
+	server, client := setupDownload(t, []int{1, 3})
+	defer server.Close()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(dir+"/tracks", os.ModePerm))
+	assert.NoError(t, os.WriteFile(dir+"/tracks/Three.mp3", nil, 0o644))
+
+	tracks := []Track{
+		{ID: "1", Title: "One"},
+		{ID: "2", Title: "Two"},
+		{ID: "3", Title: "Three"},
+	}
+
+	result, err := client.Tracks().DownloadAll(context.Background(), tracks, dir)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, result.Downloaded)
+	assert.Equal(t, 1, result.Skipped)
+	if assert.Len(t, result.Failed, 1) {
+		assert.Equal(t, "2", result.Failed[0].Track.ID)
+	}
+
+	b, err := os.ReadFile(dir + "/tracks/One.mp3")
+	assert.NoError(t, err)
+	assert.Equal(t, "mp3 of 1", string(b))
+
+	_, err = os.Stat(dir + "/tracks/Two.mp3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTracksService_DownloadAllCanceled(t *testing.T) {
+	server, client := setupDownload(t, []int{1})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := client.Tracks().DownloadAll(ctx, []Track{{ID: "1", Title: "One"}}, t.TempDir())
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, result.Downloaded)
+}
@@ -1,3 +1,7 @@
+// Package yamusic is a client of Yandex.Music API.
+//
+// Iterators returned by Iter methods, e.g. SearchService.IterTracks,
+// request pages lazily. Error is yielded once as the last element.
 package yamusic
 
 import (
@@ -5,13 +9,16 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"gopkg.in/yaml.v3"
@@ -42,9 +49,36 @@ type (
 			Log    string `yaml:"log"`
 			Host   string `yaml:"host"`
 			Port   string `yaml:"port"`
+			// Accounts are named account profiles
+			Accounts map[string]AccountProfile `yaml:"accounts"`
 		}
 
-		// Debug sets should library print debug messages or not
+		// Retry options, requests are not retried if nil
+		retry *RetryOptions
+		// Rate limiter shared by all services, requests are not limited if nil
+		limiter *RateLimiter
+		// Cache options, responses are not cached if nil
+		cache *CacheOptions
+		// Structured logger of the client and all its services
+		logger *slog.Logger
+		// Rotating log files options, logs are not written to files if nil
+		logFiles *LogFilesOptions
+		// Authenticator of requests, token from config is used if nil
+		auth *Authenticator
+		// OpenTelemetry spans and metrics of requests, no-op by default
+		telemetry *telemetry
+		// Doer without authentication shared by clients of account profiles
+		transport Doer
+		// Clients of account profiles by name
+		profiles *profileClients
+		// Description of the device sent in queue requests
+		device string
+		// Lyrics options, lyrics aren't embedded into tracks if nil
+		lyrics *LyricsOptions
+		// Plays reporting options, default options are used if nil
+		playReports *PlaysOptions
+
+		// Debug sets should default logger print debug messages or not
 		Debug bool
 		// Services
 		genres    *GenresService
@@ -53,34 +87,82 @@ type (
 		feed      *FeedService
 		playlists *PlaylistsService
 		tracks    *TracksService
+		albums    *AlbumsService
+		artists   *ArtistsService
+		likes     *LikesService
+		rotor     *RotorService
+		landing   *LandingService
+		queues    *QueuesService
+		plays     *PlaysService
 	}
 )
 
-var logDebug = log.New(os.Stdout, "[DEBUG]\t", log.Ldate|log.Ltime|log.Lshortfile)
-var logInfo = log.New(os.Stdout, "[INFO]\t", log.Ldate|log.Ltime|log.Lshortfile)
-
 // NewClient returns a new API client.
 // If a nil httpClient is provided, http.DefaultClient will be used.
 func NewClient(options ...func(*Client)) *Client {
 	baseURL, _ := url.Parse(apiURL)
 
 	c := &Client{
-		client:  http.DefaultClient,
-		baseURL: baseURL,
+		client:   http.DefaultClient,
+		baseURL:  baseURL,
+		profiles: new(profileClients),
 	}
 
+	defaultLogger := newDefaultLogger(c)
+	c.logger = defaultLogger
+	c.telemetry = newNoopTelemetry()
+
 	for _, option := range options {
 		option(c)
 	}
 
+	if c.logFiles != nil && c.logger == defaultLogger {
+		file, err := NewRotatingFile(c.config.Log, c.logFiles)
+		if err != nil {
+			c.logger.Error("cannot open log file", "dir", c.config.Log, "error", err)
+		} else {
+			c.logger = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{
+				Level: debugLeveler{client: c},
+			}))
+		}
+	}
+
+	// Every retry attempt waits for the rate limiter
+	if c.limiter != nil {
+		c.client = NewRateLimitDoer(c.client, c.limiter)
+	}
+	if c.retry != nil {
+		c.client = NewRetryDoer(c.client, c.retry)
+	}
+	// Cached responses skip both retries and the rate limiter
+	if c.cache != nil {
+		c.client = NewCacheDoer(c.client, c.cacheStore(), c.cache.TTL)
+	}
+	c.transport = c.client
+	// Token is set before the cache, so accounts don't share cached responses
+	if c.auth != nil {
+		c.client = NewAuthDoer(c.client, c.auth)
+	}
+
+	c.initServices()
+
+	return c
+}
+
+func (c *Client) initServices() {
 	c.genres = &GenresService{client: c}
 	c.search = &SearchService{client: c}
 	c.account = &AccountService{client: c}
 	c.feed = &FeedService{client: c}
 	c.playlists = &PlaylistsService{client: c}
 	c.tracks = &TracksService{client: c}
-
-	return c
+	c.albums = &AlbumsService{client: c}
+	c.artists = &ArtistsService{client: c}
+	c.likes = &LikesService{client: c}
+	c.rotor = &RotorService{client: c}
+	c.landing = &LandingService{client: c}
+	c.queues = &QueuesService{client: c}
+	c.plays = &PlaysService{client: c}
 }
 
 // HTTPClient sets http client for Yandex.Music client
@@ -110,7 +192,7 @@ func NewConfig(configPath string) func(*Client) {
 		// Open config file
 		file, err := os.Open(configPath)
 		if err != nil {
-			fmt.Println(err)
+			c.logger.Error("cannot open config", "path", configPath, "error", err)
 		}
 		defer file.Close()
 
@@ -119,25 +201,42 @@ func NewConfig(configPath string) func(*Client) {
 
 		// Start YAML decoding from file
 		if err := d.Decode(&c.config); err != nil {
-			fmt.Println(err)
+			c.logger.Error("cannot decode config", "path", configPath, "error", err)
 		}
 
 		// Create dirs if they are not exist
 		if _, err := os.Stat(c.config.Output); os.IsNotExist(err) {
 			err := os.MkdirAll(c.config.Output, os.ModePerm)
 			if err != nil {
-				fmt.Println(err)
+				c.logger.Error("cannot create output dir", "dir", c.config.Output, "error", err)
 			}
 		}
 		if _, err := os.Stat(c.config.Log); os.IsNotExist(err) {
 			err := os.MkdirAll(c.config.Log, os.ModePerm)
 			if err != nil {
-				fmt.Println(err)
+				c.logger.Error("cannot create log dir", "dir", c.config.Log, "error", err)
 			}
 		}
 	}
 }
 
+// cacheStore returns store for cached responses according to cache options
+func (c *Client) cacheStore() CacheStore {
+	if c.cache.Store != nil {
+		return c.cache.Store
+	}
+
+	if c.cache.OnDisk {
+		store, err := NewDiskCache(filepath.Join(c.config.Output, ".cache"))
+		if err == nil {
+			return store
+		}
+		c.logger.Warn("cannot use disk cache, falling back to memory", "error", err)
+	}
+
+	return NewMemoryCache(0)
+}
+
 // AccessToken sets user_id and access token for Yandex.Music client
 func AccessToken(userID int) func(*Client) {
 	return func(c *Client) {
@@ -149,6 +248,15 @@ func AccessToken(userID int) func(*Client) {
 	}
 }
 
+// OAuthToken sets access token for Yandex.Music client instead of the one
+// from config
+func OAuthToken(token string) func(*Client) {
+	return func(c *Client) {
+		c.config.Token = token
+		c.accessToken = token
+	}
+}
+
 // NewRequest creates an API request. A relative URL can be provided in urlStr,
 // in which case it is resolved relative to the BaseURL of the Client.
 // Relative URLs should always be specified without a preceding slash.  If
@@ -201,50 +309,102 @@ func (c *Client) NewRequest(
 
 // Do sends an API request and returns the API response.  The API response is
 // JSON decoded and stored in the value pointed to by v, or returned as an
-// error if an API error has occurred.  If v implements the io.Writer
+// error if an API error has occurred.  API errors are returned as *APIError
+// and can be matched with errors.Is against ErrUnauthorized, ErrNotFound,
+// ErrRateLimited and ErrRevisionConflict.  If v implements the io.Writer
 // interface, the raw response body will be written to v, without attempting to
 // first decode it.
 func (c *Client) Do(
 	ctx context.Context,
 	req *http.Request,
 	v interface{},
-) (*http.Response, error) {
+) (resp *http.Response, err error) {
+	ctx, finish := c.telemetry.startRequest(ctx, req)
+	defer func() { finish(resp, err) }()
 	req = req.WithContext(ctx)
 
-	resp, err := c.client.Do(req)
+	start := time.Now()
+	resp, err = c.client.Do(req)
 	if err != nil {
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "request failed",
+			slog.String("method", req.Method),
+			slog.String("endpoint", req.URL.Path),
+			slog.Duration("duration", time.Since(start)),
+			slog.Any("error", err),
+		)
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	if v != nil {
-		if w, ok := v.(io.Writer); ok {
-			_, err = io.Copy(w, resp.Body)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			dat, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			resp.Body = io.NopCloser(bytes.NewReader(dat))
-			err = json.Unmarshal(dat, v)
-			if err == io.EOF {
-				if c.Debug {
-					logDebug.Println("Got empty")
-				}
-				// Ignore EOF errors caused by empty response body.
-				err = nil //nolint:ineffassign
-			} else if err != nil {
-				// Try parse XML if it's not JSON.
-				err = xml.Unmarshal(dat, v) //nolint:ineffassign,staticcheck
-			}
+	if w, ok := v.(io.Writer); ok && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		_, err = io.Copy(w, resp.Body)
+		if err != nil {
+			return nil, err
 		}
+		c.logRequest(ctx, req, resp, start, nil)
+		return resp, nil
+	}
+
+	dat, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(dat))
+
+	err = checkResponse(resp, dat)
+	c.logRequest(ctx, req, resp, start, err)
+	if err != nil {
+		return resp, err
+	}
+
+	if v == nil {
+		return resp, nil
+	}
+
+	if len(bytes.TrimSpace(dat)) == 0 {
+		// Ignore empty response body.
+		return resp, nil
+	}
+
+	err = json.Unmarshal(dat, v)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Try parse XML if it's not JSON.
+		err = xml.Unmarshal(dat, v)
 	}
 
 	return resp, err
 }
 
+// logRequest logs completed request at debug level or at warn level
+// if API answered with an error
+func (c *Client) logRequest(
+	ctx context.Context,
+	req *http.Request,
+	resp *http.Response,
+	start time.Time,
+	err error,
+) {
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	if !c.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("endpoint", req.URL.Path),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	c.logger.LogAttrs(ctx, level, "request", attrs...)
+}
+
 // SetUserID sets user's id in client
 func (c *Client) SetUserID(nID int) {
 	c.userID = nID
@@ -285,6 +445,41 @@ func (c *Client) Tracks() *TracksService {
 	return c.tracks
 }
 
+// Albums returns albums service
+func (c *Client) Albums() *AlbumsService {
+	return c.albums
+}
+
+// Artists returns artists service
+func (c *Client) Artists() *ArtistsService {
+	return c.artists
+}
+
+// Likes returns likes service
+func (c *Client) Likes() *LikesService {
+	return c.likes
+}
+
+// Rotor returns radio stations service
+func (c *Client) Rotor() *RotorService {
+	return c.rotor
+}
+
+// Landing returns landing, charts and new releases service
+func (c *Client) Landing() *LandingService {
+	return c.landing
+}
+
+// Queues returns play queues service
+func (c *Client) Queues() *QueuesService {
+	return c.queues
+}
+
+// Plays returns plays reporting service
+func (c *Client) Plays() *PlaysService {
+	return c.plays
+}
+
 // General types
 type (
 	// InvocationInfo is base info in all requests
@@ -301,43 +496,74 @@ type (
 	}
 )
 
-// print playlist structure to console or file
-func (c *Client) PrintPlaylists() {
-	result, _, _ := c.Playlists().List(context.Background(), 0)
+// decodeTagged decodes data of tagged union into the target of its type.
+// Targets are addresses of nil pointer fields by type, so only the field
+// of the type gets allocated. Data of unknown type is left undecoded.
+func decodeTagged[T ~string](data []byte, typ T, targets map[T]any) error {
+	target, ok := targets[typ]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(data, target)
+}
+
+// PrintPlaylists writes kinds and titles of user's playlists to w
+func (c *Client) PrintPlaylists(ctx context.Context, w io.Writer) error {
+	result, _, err := c.Playlists().List(ctx, 0)
+	if err != nil {
+		return err
+	}
 	playlists := result.Result
-	fmt.Println("User ", c.UserID(), " playlists:")
+	fmt.Fprintln(w, "User ", c.UserID(), " playlists:")
 	for _, playlist := range playlists {
-		fmt.Println("\t", playlist.Kind, ": ", playlist.Title)
+		fmt.Fprintln(w, "\t", playlist.Kind, ": ", playlist.Title)
 	}
+	return nil
 }
 
-// print playlist structure to console or file
-func (c *Client) GetTracksWithoutPlaylist() []Track {
+// GetTracksWithoutPlaylist returns liked tracks that are in no playlist
+// of the user
+func (c *Client) GetTracksWithoutPlaylist(ctx context.Context) ([]Track, error) {
 	// Get like tracks
 	var tracks_without_playlist []Track
 	var track_ids []string
 	tracks_in_playlist := map[string]bool{}
-	res1, _, _ := c.Tracks().GetLike(context.Background())
+	res1, _, err := c.Tracks().GetLike(ctx)
+	if err != nil {
+		return nil, err
+	}
 	for _, track := range res1.Result.Library.Tracks {
 		track_ids = append(track_ids, track.ID)
 	}
-	res2, _, _ := c.Tracks().GetAll(context.Background(), track_ids)
-	like_tracks := res2.Result // []Track
+	res2, err := c.Tracks().GetAll(ctx, track_ids, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(res2.Missing) > 0 {
+		c.logger.Warn("liked tracks not found", "count", len(res2.Missing), "ids", res2.Missing)
+	}
+	like_tracks := res2.Tracks // []Track
 
 	// Search for playlists
-	result, _, _ := c.Playlists().List(context.Background(), 0)
+	result, _, err := c.Playlists().List(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
 	playlists := result.Result
 	bar := progressbar.Default(int64(len(playlists)))
 	for _, playlist := range playlists {
 		bar.Add(1)
-		result, _, _ := c.Playlists().Get(context.Background(), c.UserID(), playlist.Kind)
+		result, _, err := c.Playlists().Get(ctx, c.UserID(), playlist.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("playlist %d: %w", playlist.Kind, err)
+		}
 		tracks := result.Result.Tracks
 		for _, track := range tracks {
 			tracks_in_playlist[track.Track.ID] = true
 		}
 	}
-	fmt.Println(len(tracks_in_playlist))
-	fmt.Println(len(like_tracks))
+	c.logger.Info("tracks in playlists", "count", len(tracks_in_playlist))
+	c.logger.Info("liked tracks", "count", len(like_tracks))
 
 	for _, track := range like_tracks {
 		if !tracks_in_playlist[track.ID] {
@@ -345,13 +571,13 @@ func (c *Client) GetTracksWithoutPlaylist() []Track {
 		}
 	}
 
-	fmt.Println(len(tracks_without_playlist))
+	c.logger.Info("tracks without playlist", "count", len(tracks_without_playlist))
 
-	return tracks_without_playlist
+	return tracks_without_playlist, nil
 }
 
 // Test
-func (c *Client) TestIneraction() {
+func (c *Client) TestIneraction(ctx context.Context) error {
 	client := NewClient(
 		// read app config
 		NewConfig("yamusic_config.yaml"),
@@ -360,27 +586,40 @@ func (c *Client) TestIneraction() {
 		AccessToken(0),
 	)
 	// get user by auth token
-	accountStatus, _, _ := client.Account().GetUser(context.Background())
+	accountStatus, _, err := client.Account().GetUser(ctx)
+	if err != nil {
+		return err
+	}
 	// add userID to client
 	client.SetUserID(accountStatus.Result.UID)
 	option := 0
 	switch option {
 	case 1: // Download Like playlist (kind - 3)
-		client.Playlists().DownloadOne(context.Background(), 3)
+		_, err = client.Playlists().DownloadOne(ctx, 3)
+		return err
 	case 2: // Create, Rename, Delete playlist
-		res1, _, _ := client.Playlists().Create(context.Background(), "Test1", true)
-		log.Println(res1.Result.Title)
+		res1, _, err := client.Playlists().Create(ctx, "Test1", true)
+		if err != nil {
+			return err
+		}
+		client.logger.Info("created playlist", "title", res1.Result.Title)
 		kind := res1.Result.Kind
-		res2, _, _ := client.Playlists().Rename(context.Background(), kind, "Test2")
-		log.Println(res2.Result.Title)
-		client.Playlists().Delete(context.Background(), kind)
+		res2, _, err := client.Playlists().Rename(ctx, kind, "Test2")
+		if err != nil {
+			return err
+		}
+		client.logger.Info("renamed playlist", "title", res2.Result.Title)
+		_, _, err = client.Playlists().Delete(ctx, kind)
+		return err
 	case 3:
-		client.Playlists().DistributeTracksByPlaylists()
+		_, err = client.Playlists().DistributeTracksByPlaylists(ctx)
+		return err
 
 	case 4:
-		client.Playlists().DeleteTracksFromPlaylists()
+		return client.Playlists().DeleteTracksFromPlaylists(ctx)
 
 	default:
-		log.Printf("Don`t use yamusic\n")
+		client.logger.Info("Don`t use yamusic")
 	}
+	return nil
 }
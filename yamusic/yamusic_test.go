@@ -22,21 +22,20 @@ var (
 // setup sets up a test HTTP server along with a yamusic.Client that is
 // configured to talk to that test server. Tests should register handlers on
 // mux which provide mock responses for the API method being tested.
-func setup() {
+// Additional options are applied to the client after the default ones.
+func setup(options ...func(*Client)) {
 	// test server
 	mux = http.NewServeMux()
 	server = httptest.NewServer(mux)
 
 	url, _ := url.Parse(server.URL + "/")
 
-	logInfo.Println("fabrqbtrebqrwbrqbtrq")
-
 	// yamusic client configured to use test server
-	client = NewClient(
+	client = NewClient(append([]func(*Client){
 		BaseURL(url),
-		NewConfig("yamusic_config.yaml"),
-		AccessToken(0),
-	)
+		func(c *Client) { c.config.Token = accessToken },
+		AccessToken(userID),
+	}, options...)...)
 }
 
 // teardown closes the test HTTP server.
@@ -0,0 +1,56 @@
+package yamusictest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"awesome/yamusic"
+)
+
+// Plays returns plays reported by the user in order of reporting
+func (s *Server) Plays(uid int) []yamusic.Play {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.mustUser(uid).plays)
+}
+
+func (s *Server) reportPlays(w http.ResponseWriter, r *http.Request, u *user) {
+	if _, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("client-now")); err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", "wrong client-now")
+		return
+	}
+
+	var body struct {
+		Plays []yamusic.Play `json:"plays"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", err.Error())
+		return
+	}
+	for _, play := range body.Plays {
+		trackID, _, _ := strings.Cut(play.TrackID, ":")
+		if _, ok := s.tracks[trackID]; !ok {
+			s.writeError(w, http.StatusBadRequest, "validate", "track "+play.TrackID+" not found")
+			return
+		}
+		if play.PlayID == "" {
+			s.writeError(w, http.StatusBadRequest, "validate", "playId is required")
+			return
+		}
+	}
+
+	// Play reported again is counted once like by the real API
+	for _, play := range body.Plays {
+		reported := slices.ContainsFunc(u.plays, func(p yamusic.Play) bool {
+			return p.PlayID == play.PlayID
+		})
+		if !reported {
+			u.plays = append(u.plays, play)
+		}
+	}
+	s.writeResult(w, "ok")
+}
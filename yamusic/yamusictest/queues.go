@@ -0,0 +1,130 @@
+package yamusictest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"awesome/yamusic"
+)
+
+// AddQueue adds queue of the user as if it was created on another device
+// and returns its id
+func (s *Server) AddQueue(uid int, queue yamusic.Queue) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range queue.Tracks {
+		s.mustTrack(t.TrackID)
+	}
+	return s.addQueue(s.mustUser(uid), queue)
+}
+
+// Queue returns queue of the user by id
+func (s *Server) Queue(uid int, id string) (yamusic.Queue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if q := s.mustUser(uid).queue(id); q != nil {
+		return *q, true
+	}
+	return yamusic.Queue{}, false
+}
+
+// withDevice returns handler answering 400 to requests without device
+func (s *Server) withDevice(h func(w http.ResponseWriter, r *http.Request, u *user)) func(w http.ResponseWriter, r *http.Request, u *user) {
+	return func(w http.ResponseWriter, r *http.Request, u *user) {
+		if !strings.Contains(r.Header.Get("X-Yandex-Music-Device"), "device_id=") {
+			s.writeError(w, http.StatusBadRequest, "validate", "device is required")
+			return
+		}
+		h(w, r, u)
+	}
+}
+
+func (s *Server) listQueues(w http.ResponseWriter, r *http.Request, u *user) {
+	queues := new(yamusic.QueuesListResp)
+	queues.Result.Queues = []yamusic.QueueItem{}
+	for _, q := range u.queues {
+		queues.Result.Queues = append(queues.Result.Queues, yamusic.QueueItem{
+			ID:       q.ID,
+			Context:  q.Context,
+			Modified: q.Modified,
+		})
+	}
+	slices.SortStableFunc(queues.Result.Queues, func(a, b yamusic.QueueItem) int {
+		return b.Modified.Compare(a.Modified)
+	})
+	s.writeResult(w, queues.Result)
+}
+
+func (s *Server) getQueue(w http.ResponseWriter, r *http.Request, u *user) {
+	q := u.queue(r.PathValue("id"))
+	if q == nil {
+		s.writeError(w, http.StatusNotFound, "not-found", "queue not found")
+		return
+	}
+	s.writeResult(w, q)
+}
+
+func (s *Server) createQueue(w http.ResponseWriter, r *http.Request, u *user) {
+	var queue yamusic.Queue
+	if err := json.NewDecoder(r.Body).Decode(&queue); err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", err.Error())
+		return
+	}
+	for _, t := range queue.Tracks {
+		if _, ok := s.tracks[t.TrackID]; !ok {
+			s.writeError(w, http.StatusBadRequest, "validate", "track "+t.TrackID+" not found")
+			return
+		}
+	}
+
+	created := new(yamusic.QueueCreateResp)
+	created.Result.ID = s.addQueue(u, queue)
+	created.Result.MostRecentQueueID = created.Result.ID
+	s.writeResult(w, created.Result)
+}
+
+func (s *Server) updateQueuePosition(w http.ResponseWriter, r *http.Request, u *user) {
+	q := u.queue(r.PathValue("id"))
+	if q == nil {
+		s.writeError(w, http.StatusNotFound, "not-found", "queue not found")
+		return
+	}
+	index, err := strconv.Atoi(r.URL.Query().Get("currentIndex"))
+	if err != nil || index < 0 || index >= len(q.Tracks) {
+		s.writeError(w, http.StatusBadRequest, "validate", "wrong currentIndex")
+		return
+	}
+
+	q.CurrentIndex = index
+	q.Modified = time.Now()
+
+	updated := new(yamusic.QueueUpdateResp)
+	updated.Result.Status = "ok"
+	s.writeResult(w, updated.Result)
+}
+
+// addQueue adds queue to queues of the user and returns its new id
+func (s *Server) addQueue(u *user, queue yamusic.Queue) string {
+	s.queueID++
+	queue.ID = fmt.Sprintf("%024x", s.queueID)
+	queue.Modified = time.Now()
+	u.queues = append(u.queues, &queue)
+	return queue.ID
+}
+
+// queue returns queue of the user by id or nil
+func (u *user) queue(id string) *yamusic.Queue {
+	for _, q := range u.queues {
+		if q.ID == id {
+			return q
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package yamusictest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"awesome/yamusic"
+)
+
+// stationBatchSize is number of tracks in a batch of station tracks
+const stationBatchSize = 5
+
+type (
+	station struct {
+		yamusic.StationResult
+		trackIDs []string
+	}
+	// radio is a state of station played by user
+	radio struct {
+		settings yamusic.StationSettings
+		next     int
+		batches  int
+		feedback []yamusic.RotorFeedback
+	}
+)
+
+// AddStation adds radio station endlessly playing tracks by IDs in a loop
+func (s *Server) AddStation(id yamusic.StationID, name string, trackIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, trackID := range trackIDs {
+		s.mustTrack(trackID)
+	}
+
+	st := &station{trackIDs: trackIDs}
+	st.Station.ID = id
+	st.Station.Name = name
+	st.Station.IDForFrom = id.Tag
+	st.Settings2 = yamusic.StationSettings{Language: "any", MoodEnergy: "all", Diversity: "default"}
+	s.stations = append(s.stations, st)
+}
+
+// Feedback returns events the user sent to station
+func (s *Server) Feedback(uid int, id yamusic.StationID) []yamusic.RotorFeedback {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.mustUser(uid).radios[id.String()]; ok {
+		return slices.Clone(r.feedback)
+	}
+	return nil
+}
+
+func (s *Server) listStations(w http.ResponseWriter, r *http.Request, u *user) {
+	result := []yamusic.StationResult{}
+	for _, st := range s.stations {
+		result = append(result, s.stationResult(st, u))
+	}
+	s.writeResult(w, result)
+}
+
+func (s *Server) getDashboard(w http.ResponseWriter, r *http.Request, u *user) {
+	dashboard := new(yamusic.RotorDashboardResp)
+	dashboard.Result.DashboardID = fmt.Sprintf("dashboard-%d", u.uid)
+	dashboard.Result.Stations = []yamusic.StationResult{}
+	for _, st := range s.stations {
+		dashboard.Result.Stations = append(dashboard.Result.Stations, s.stationResult(st, u))
+	}
+	s.writeResult(w, dashboard.Result)
+}
+
+func (s *Server) getStationInfo(w http.ResponseWriter, r *http.Request, u *user) {
+	st, ok := s.station(w, r)
+	if !ok {
+		return
+	}
+	s.writeResult(w, []yamusic.StationResult{s.stationResult(st, u)})
+}
+
+func (s *Server) setStationSettings(w http.ResponseWriter, r *http.Request, u *user) {
+	st, ok := s.station(w, r)
+	if !ok {
+		return
+	}
+
+	var settings yamusic.StationSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", err.Error())
+		return
+	}
+	u.radio(st).settings = settings
+	s.writeResult(w, "ok")
+}
+
+func (s *Server) getStationTracks(w http.ResponseWriter, r *http.Request, u *user) {
+	st, ok := s.station(w, r)
+	if !ok {
+		return
+	}
+
+	rd := u.radio(st)
+	if queue := r.URL.Query().Get("queue"); queue != "" {
+		queue, _, _ = strings.Cut(queue, ":")
+		if i := slices.Index(st.trackIDs, queue); i >= 0 {
+			rd.next = i + 1
+		}
+	}
+	rd.batches++
+
+	tracks := new(yamusic.RotorTracksResp)
+	tracks.Result.ID = st.Station.ID
+	tracks.Result.BatchID = fmt.Sprintf("%v.%d", st.Station.ID, rd.batches)
+	for range min(stationBatchSize, len(st.trackIDs)) {
+		t := s.tracks[st.trackIDs[rd.next%len(st.trackIDs)]]
+		rd.next = (rd.next + 1) % len(st.trackIDs)
+
+		tracks.Result.Sequence = append(tracks.Result.Sequence, yamusic.StationTrack{
+			Type:  "track",
+			Track: t.Track,
+			Liked: slices.ContainsFunc(u.likes["likes/track"], func(l like) bool { return l.id == t.ID }),
+		})
+	}
+	s.writeResult(w, tracks.Result)
+}
+
+func (s *Server) sendStationFeedback(w http.ResponseWriter, r *http.Request, u *user) {
+	st, ok := s.station(w, r)
+	if !ok {
+		return
+	}
+
+	var feedback yamusic.RotorFeedback
+	if err := json.NewDecoder(r.Body).Decode(&feedback); err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", err.Error())
+		return
+	}
+	switch feedback.Type {
+	case yamusic.RotorRadioStarted:
+	case yamusic.RotorTrackStarted, yamusic.RotorTrackFinished, yamusic.RotorSkip:
+		if feedback.TrackID == "" {
+			s.writeError(w, http.StatusBadRequest, "validate", "trackId is required")
+			return
+		}
+	default:
+		s.writeError(w, http.StatusBadRequest, "validate", "unknown type "+string(feedback.Type))
+		return
+	}
+	feedback.BatchID = r.URL.Query().Get("batch-id")
+
+	rd := u.radio(st)
+	rd.feedback = append(rd.feedback, feedback)
+	s.writeResult(w, "ok")
+}
+
+// station returns station by id from path or answers 404
+func (s *Server) station(w http.ResponseWriter, r *http.Request) (*station, bool) {
+	id := r.PathValue("station")
+	for _, st := range s.stations {
+		if st.Station.ID.String() == id {
+			return st, true
+		}
+	}
+	s.writeError(w, http.StatusNotFound, "not-found", "station not found")
+	return nil, false
+}
+
+// stationResult returns station with settings of the user
+func (s *Server) stationResult(st *station, u *user) yamusic.StationResult {
+	result := st.StationResult
+	if rd, ok := u.radios[st.Station.ID.String()]; ok {
+		result.Settings2 = rd.settings
+	}
+	return result
+}
+
+// radio returns state of station played by the user
+func (u *user) radio(st *station) *radio {
+	id := st.Station.ID.String()
+	rd, ok := u.radios[id]
+	if !ok {
+		rd = &radio{settings: st.Settings2}
+		u.radios[id] = rd
+	}
+	return rd
+}
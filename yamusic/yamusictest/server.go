@@ -0,0 +1,1226 @@
+// Package yamusictest provides an in-memory fake Yandex.Music API server
+// for offline tests of code using yamusic.Client.
+//
+//	server := yamusictest.NewServer()
+//	defer server.Close()
+//	server.AddUser(42, "user", "token")
+//	server.AddTrack(track, []byte("mp3"))
+//	kind := server.AddPlaylist(42, "Rock", track.ID)
+//	client := server.NewClient(42)
+//
+// The server keeps users, their playlists with revisions, liked tracks,
+// tracks with plain and synced lyrics, files of tracks, custom covers
+// of playlists and reported plays. Playlist changes are applied with
+// the same revision semantics as the real API: a change of a stale
+// revision is rejected with 412 wrong-revision.
+package yamusictest
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"awesome/yamusic"
+)
+
+// signPrefix is a salt of storage links, the same as the client uses
+const signPrefix = "XGRlBW9FXlekgbPrRHuSiA"
+
+// lyricsSignKey is a key of lyrics requests signature, the same as
+// the client uses
+const lyricsSignKey = "p93jhgh689SBReK6ghtw62"
+
+type (
+	// Server is a fake Yandex.Music API server
+	Server struct {
+		// URL is base URL of the server
+		URL string
+
+		server *httptest.Server
+
+		mu     sync.Mutex
+		users  map[int]*user
+		tokens map[string]int
+		tracks map[string]*track
+		// stations are radio stations in order of adding
+		stations []*station
+		reqID    int
+		queueID  int
+	}
+	user struct {
+		uid       int
+		login     string
+		playlists map[int]*playlist
+		nextKind  int
+		// likes are liked and disliked objects by list and type,
+		// e.g. "likes/track", the latest first
+		likes         map[string][]like
+		likesRevision int
+		// radios are states of stations played by the user by station id
+		radios map[string]*radio
+		queues []*yamusic.Queue
+		// plays are plays reported by the user
+		plays []yamusic.Play
+	}
+	like struct {
+		id        string
+		timestamp time.Time
+	}
+	playlist struct {
+		yamusic.PlaylistsResult
+		tracks []playlistTrack
+		// cover is custom cover image uploaded by the owner
+		cover []byte
+	}
+	playlistTrack struct {
+		id        string
+		albumID   int
+		timestamp time.Time
+	}
+	track struct {
+		yamusic.Track
+		file       []byte
+		lyrics     string
+		syncLyrics string
+	}
+	// diffOp is an operation of change-relative diff
+	diffOp struct {
+		Op     string                   `json:"op"`
+		At     int                      `json:"at"`
+		From   int                      `json:"from"`
+		To     int                      `json:"to"`
+		Tracks []yamusic.PlaylistsTrack `json:"tracks"`
+	}
+	// envelope is a body of every API response
+	envelope struct {
+		InvocationInfo yamusic.InvocationInfo `json:"invocationInfo"`
+		Error          *yamusic.Error         `json:"error,omitempty"`
+		Result         any                    `json:"result,omitempty"`
+	}
+)
+
+// NewServer starts and returns a new TLS server. The caller should call
+// Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		users:  make(map[int]*user),
+		tokens: make(map[string]int),
+		tracks: make(map[string]*track),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /account/status", s.authorized(s.accountStatus))
+	mux.HandleFunc("GET /users/{uid}/playlists/list", s.authorized(s.listPlaylists))
+	mux.HandleFunc("GET /users/{uid}/playlists", s.authorized(s.getPlaylistsByKinds))
+	mux.HandleFunc("GET /users/{uid}/playlists/{kind}", s.authorized(s.getPlaylist))
+	mux.HandleFunc("POST /users/{uid}/playlists/create", s.authorized(s.createPlaylist))
+	mux.HandleFunc("POST /users/{uid}/playlists/{kind}/name", s.authorized(s.renamePlaylist))
+	mux.HandleFunc("POST /users/{uid}/playlists/{kind}/description", s.authorized(s.setPlaylistDescription))
+	mux.HandleFunc("POST /users/{uid}/playlists/{kind}/visibility", s.authorized(s.setPlaylistVisibility))
+	mux.HandleFunc("POST /users/{uid}/playlists/{kind}/cover/upload", s.authorized(s.uploadPlaylistCover))
+	mux.HandleFunc("POST /users/{uid}/playlists/{kind}/cover/clear", s.authorized(s.clearPlaylistCover))
+	mux.HandleFunc("POST /users/{uid}/playlists/{kind}/delete", s.authorized(s.deletePlaylist))
+	mux.HandleFunc("POST /users/{uid}/playlists/{kind}/change-relative", s.authorized(s.changePlaylist))
+	mux.HandleFunc("GET /users/{uid}/playlists/{kind}/recommendations", s.authorized(s.getRecommendations))
+	for _, list := range []string{"likes", "dislikes"} {
+		mux.HandleFunc("GET /users/{uid}/"+list+"/{types}", s.authorized(s.getLikes(list)))
+		mux.HandleFunc("POST /users/{uid}/"+list+"/{types}/{action}", s.authorized(s.changeLikes(list)))
+	}
+	mux.HandleFunc("POST /tracks", s.authorized(s.getTracks))
+	mux.HandleFunc("GET /tracks/{id}", s.authorized(s.getTrack))
+	mux.HandleFunc("GET /tracks/{id}/supplement", s.authorized(s.getSupplement))
+	mux.HandleFunc("GET /tracks/{id}/similar", s.authorized(s.getSimilarTracks))
+	mux.HandleFunc("GET /tracks/{id}/lyrics", s.authorized(s.getLyrics))
+	mux.HandleFunc("GET /tracks/{id}/download-info", s.authorized(s.getDownloadInfo))
+	mux.HandleFunc("GET /albums/{id}", s.authorized(s.getAlbum))
+	mux.HandleFunc("GET /albums/{id}/with-tracks", s.authorized(s.getAlbumWithTracks))
+	mux.HandleFunc("POST /albums", s.authorized(s.getAlbums))
+	mux.HandleFunc("GET /rotor/stations/list", s.authorized(s.listStations))
+	mux.HandleFunc("GET /rotor/stations/dashboard", s.authorized(s.getDashboard))
+	mux.HandleFunc("GET /rotor/station/{station}/info", s.authorized(s.getStationInfo))
+	mux.HandleFunc("POST /rotor/station/{station}/settings2", s.authorized(s.setStationSettings))
+	mux.HandleFunc("GET /rotor/station/{station}/tracks", s.authorized(s.getStationTracks))
+	mux.HandleFunc("POST /rotor/station/{station}/feedback", s.authorized(s.sendStationFeedback))
+	mux.HandleFunc("GET /queues", s.authorized(s.withDevice(s.listQueues)))
+	mux.HandleFunc("GET /queues/{id}", s.authorized(s.withDevice(s.getQueue)))
+	mux.HandleFunc("POST /queues", s.authorized(s.withDevice(s.createQueue)))
+	mux.HandleFunc("POST /queues/{id}/update-position", s.authorized(s.withDevice(s.updateQueuePosition)))
+	mux.HandleFunc("POST /plays", s.authorized(s.reportPlays))
+	mux.HandleFunc("GET /download-info/{id}", s.downloadInfo)
+	mux.HandleFunc("GET /get-mp3/{sign}/{ts}/{id}", s.storage)
+	mux.HandleFunc("GET /lyrics/{id}/{format}", s.lyricsStorage)
+
+	s.server = httptest.NewTLSServer(mux)
+	s.URL = s.server.URL
+
+	return s
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns HTTP client trusting the server's certificate
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+// NewClient returns Yandex.Music client of the user by uid talking
+// to the server. Options are applied before the ones connecting the client
+// to the server.
+func (s *Server) NewClient(uid int, options ...func(*yamusic.Client)) *yamusic.Client {
+	s.mu.Lock()
+	var token string
+	for t, id := range s.tokens {
+		if id == uid {
+			token = t
+		}
+	}
+	s.mu.Unlock()
+
+	baseURL, _ := url.Parse(s.URL + "/")
+	options = append(options,
+		yamusic.BaseURL(baseURL),
+		yamusic.HTTPClient(s.Client()),
+		yamusic.OAuthToken(token),
+		yamusic.AccessToken(uid),
+	)
+	return yamusic.NewClient(options...)
+}
+
+// AddUser adds user authorized by token
+func (s *Server) AddUser(uid int, login, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[uid] = &user{
+		uid:       uid,
+		login:     login,
+		playlists: make(map[int]*playlist),
+		nextKind:  1000,
+		likes:     make(map[string][]like),
+		radios:    make(map[string]*radio),
+	}
+	s.tokens[token] = uid
+}
+
+// AddTrack adds track with content of its file. Track must have a numeric
+// ID and at least one album to be added to playlists.
+func (s *Server) AddTrack(t yamusic.Track, file []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tracks[t.ID] = &track{Track: t, file: file}
+}
+
+// SetLyrics sets lyrics of track by ID and makes them available
+func (s *Server) SetLyrics(trackID, lyrics string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.mustTrack(trackID)
+	t.lyrics = lyrics
+	t.LyricsAvailable = true
+	t.LyricsInfo.HasAvailableTextLyrics = true
+}
+
+// SetSyncLyrics sets lyrics of track by ID in LRC format and makes them
+// available
+func (s *Server) SetSyncLyrics(trackID, lrc string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.mustTrack(trackID)
+	t.syncLyrics = lrc
+	t.LyricsInfo.HasAvailableSyncLyrics = true
+}
+
+// AddPlaylist creates playlist of the user with tracks by IDs
+// and returns its kind
+func (s *Server) AddPlaylist(uid int, title string, trackIDs ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.mustUser(uid).newPlaylist(title, "public")
+	for _, id := range trackIDs {
+		p.tracks = append(p.tracks, s.newPlaylistTrack(id))
+	}
+	s.touch(p)
+	return p.Kind
+}
+
+// AddPlaylistTracks appends tracks by IDs to playlist and increments its
+// revision as if the playlist was changed by another client
+func (s *Server) AddPlaylistTracks(uid, kind int, trackIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.mustPlaylist(uid, kind)
+	for _, id := range trackIDs {
+		p.tracks = append(p.tracks, s.newPlaylistTrack(id))
+	}
+	p.Revision++
+	s.touch(p)
+}
+
+// Playlist returns playlist of the user by kind
+func (s *Server) Playlist(uid, kind int) (yamusic.PlaylistWithTracks, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[uid]
+	if !ok {
+		return yamusic.PlaylistWithTracks{}, false
+	}
+	p, ok := u.playlists[kind]
+	if !ok {
+		return yamusic.PlaylistWithTracks{}, false
+	}
+	return s.playlistWithTracks(p), true
+}
+
+// PlaylistCover returns custom cover image of playlist of the user
+// or nil if it has none
+func (s *Server) PlaylistCover(uid, kind int) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[uid]
+	if !ok {
+		return nil
+	}
+	if p, ok := u.playlists[kind]; ok {
+		return p.cover
+	}
+	return nil
+}
+
+// Like adds tracks by IDs to liked tracks of the user
+func (s *Server) Like(uid int, trackIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.mustUser(uid)
+	for _, id := range trackIDs {
+		s.mustTrack(id)
+	}
+	u.change("likes", yamusic.LikeTrack, "add-multiple", trackIDs)
+}
+
+// Likes returns ids of objects of type liked by the user, the latest first
+func (s *Server) Likes(uid int, typ yamusic.LikeType) []string {
+	return s.likeIDs(uid, "likes", typ)
+}
+
+// Dislikes returns ids of objects of type disliked by the user,
+// the latest first
+func (s *Server) Dislikes(uid int, typ yamusic.LikeType) []string {
+	return s.likeIDs(uid, "dislikes", typ)
+}
+
+func (s *Server) likeIDs(uid int, list string, typ yamusic.LikeType) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for _, l := range s.mustUser(uid).likes[list+"/"+string(typ)] {
+		ids = append(ids, l.id)
+	}
+	return ids
+}
+
+// authorized returns handler answering 401 to requests without token
+// of a known user
+func (s *Server) authorized(h func(w http.ResponseWriter, r *http.Request, u *user)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "OAuth ")
+		uid, known := s.tokens[token]
+		if !ok || !known {
+			s.writeError(w, http.StatusUnauthorized, "session-expired", "invalid oauth token")
+			return
+		}
+		h(w, r, s.users[uid])
+	}
+}
+
+func (s *Server) accountStatus(w http.ResponseWriter, r *http.Request, u *user) {
+	status := new(yamusic.AccountStatusResp)
+	status.Result.Account.UID = u.uid
+	status.Result.Account.Login = u.login
+	status.Result.Account.Now = time.Now()
+	s.writeResult(w, status.Result)
+}
+
+func (s *Server) listPlaylists(w http.ResponseWriter, r *http.Request, u *user) {
+	owner, ok := s.owner(w, r)
+	if !ok {
+		return
+	}
+
+	result := []yamusic.PlaylistsResult{}
+	for _, kind := range sortedKinds(owner) {
+		p := owner.playlists[kind]
+		if owner == u || p.Visibility == "public" {
+			result = append(result, p.PlaylistsResult)
+		}
+	}
+	s.writeResult(w, result)
+}
+
+func (s *Server) getPlaylistsByKinds(w http.ResponseWriter, r *http.Request, u *user) {
+	owner, ok := s.owner(w, r)
+	if !ok {
+		return
+	}
+
+	type trackID struct {
+		ID        int       `json:"id"`
+		AlbumID   int       `json:"albumId"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+	type playlistWithIDs struct {
+		yamusic.PlaylistsResult
+		Tracks []trackID `json:"tracks"`
+	}
+
+	result := []playlistWithIDs{}
+	for _, value := range strings.Split(r.URL.Query().Get("kinds"), ",") {
+		kind, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		p, ok := owner.playlists[kind]
+		if !ok || (owner != u && p.Visibility != "public") {
+			continue
+		}
+
+		withIDs := playlistWithIDs{PlaylistsResult: p.PlaylistsResult, Tracks: []trackID{}}
+		for _, t := range p.tracks {
+			id, _ := strconv.Atoi(t.id)
+			withIDs.Tracks = append(withIDs.Tracks, trackID{ID: id, AlbumID: t.albumID, Timestamp: t.timestamp})
+		}
+		result = append(result, withIDs)
+	}
+	s.writeResult(w, result)
+}
+
+func (s *Server) getPlaylist(w http.ResponseWriter, r *http.Request, u *user) {
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+	if p.UID != u.uid && p.Visibility != "public" {
+		s.writeError(w, http.StatusNotFound, "not-found", "playlist not found")
+		return
+	}
+	s.writeResult(w, s.playlistWithTracks(p))
+}
+
+func (s *Server) createPlaylist(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+
+	visibility := r.FormValue("visibility")
+	if visibility != "public" && visibility != "private" {
+		s.writeError(w, http.StatusBadRequest, "validate", "wrong visibility")
+		return
+	}
+
+	p := u.newPlaylist(r.FormValue("title"), visibility)
+	s.touch(p)
+	s.writeResult(w, p.PlaylistsResult)
+}
+
+func (s *Server) renamePlaylist(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	p.Title = r.FormValue("value")
+	p.Revision++
+	s.touch(p)
+	s.writeResult(w, p.PlaylistsResult)
+}
+
+func (s *Server) setPlaylistDescription(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	p.Description = r.FormValue("value")
+	p.Revision++
+	s.touch(p)
+	s.writeResult(w, p.PlaylistsResult)
+}
+
+func (s *Server) setPlaylistVisibility(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	visibility := r.FormValue("value")
+	if visibility != "public" && visibility != "private" {
+		s.writeError(w, http.StatusBadRequest, "validate", "wrong visibility")
+		return
+	}
+
+	p.Visibility = visibility
+	p.Revision++
+	s.touch(p)
+	s.writeResult(w, p.PlaylistsResult)
+}
+
+func (s *Server) uploadPlaylistCover(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", "image is required")
+		return
+	}
+	defer file.Close()
+	contentType := header.Header.Get("Content-Type")
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		s.writeError(w, http.StatusBadRequest, "validate", "image should be JPEG or PNG")
+		return
+	}
+	image, err := io.ReadAll(file)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", err.Error())
+		return
+	}
+
+	p.cover = image
+	p.Revision++
+	p.Cover = yamusic.PlaylistsCover{
+		Type:    "pic",
+		Custom:  true,
+		Dir:     fmt.Sprintf("/get-music-user-playlist/%d/%d", p.UID, p.Kind),
+		Version: strconv.Itoa(p.Revision),
+		URI:     fmt.Sprintf("avatars.yandex.net/get-music-user-playlist/%d/%d/%%%%", p.UID, p.Kind),
+	}
+	s.touch(p)
+	s.writeResult(w, p.PlaylistsResult)
+}
+
+func (s *Server) clearPlaylistCover(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	p.cover = nil
+	p.Revision++
+	p.Cover = yamusic.PlaylistsCover{}
+	s.touch(p)
+	s.writeResult(w, p.PlaylistsResult)
+}
+
+func (s *Server) deletePlaylist(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	delete(u.playlists, p.Kind)
+	s.writeResult(w, "ok")
+}
+
+func (s *Server) changePlaylist(w http.ResponseWriter, r *http.Request, u *user) {
+	if !s.own(w, r, u) {
+		return
+	}
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	revision, err := strconv.Atoi(r.FormValue("revision"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", "wrong revision")
+		return
+	}
+	if revision != p.Revision {
+		s.writeError(w, http.StatusPreconditionFailed, "wrong-revision",
+			fmt.Sprintf("revision %d is stale, current is %d", revision, p.Revision))
+		return
+	}
+
+	var diff []diffOp
+	if err := json.Unmarshal([]byte(r.FormValue("diff")), &diff); err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", "wrong diff")
+		return
+	}
+
+	tracks, err := s.apply(p.tracks, diff)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "validate", err.Error())
+		return
+	}
+
+	p.tracks = tracks
+	p.Revision++
+	s.touch(p)
+	s.writeResult(w, p.PlaylistsResult)
+}
+
+// getLikes returns handler of liked or disliked objects by list
+func (s *Server) getLikes(list string) func(w http.ResponseWriter, r *http.Request, u *user) {
+	return func(w http.ResponseWriter, r *http.Request, u *user) {
+		owner, ok := s.owner(w, r)
+		if !ok {
+			return
+		}
+
+		typ := yamusic.LikeType(strings.TrimSuffix(r.PathValue("types"), "s"))
+		likes := owner.likes[list+"/"+string(typ)]
+
+		switch typ {
+		case yamusic.LikeTrack:
+			tracks := new(yamusic.LikeTracksResp)
+			tracks.Result.Library.UID = owner.uid
+			tracks.Result.Library.Revision = owner.likesRevision
+			tracks.Result.Library.Tracks = []yamusic.TrackLike{}
+			for _, l := range likes {
+				like := yamusic.TrackLike{ID: l.id, Timestamp: l.timestamp}
+				if t, ok := s.tracks[l.id]; ok && len(t.Albums) > 0 {
+					like.AlbumId = strconv.Itoa(t.Albums[0].ID)
+				}
+				tracks.Result.Library.Tracks = append(tracks.Result.Library.Tracks, like)
+			}
+			s.writeResult(w, tracks.Result)
+		case yamusic.LikeAlbum:
+			albums := []yamusic.LikedAlbum{}
+			for _, l := range likes {
+				album, _ := s.albumWithTracks(l.id)
+				id, _ := strconv.Atoi(l.id)
+				albums = append(albums, yamusic.LikedAlbum{ID: id, Timestamp: l.timestamp, Album: album.Album})
+			}
+			s.writeResult(w, albums)
+		case yamusic.LikeArtist:
+			artists := []yamusic.LikedArtist{}
+			for _, l := range likes {
+				artists = append(artists, yamusic.LikedArtist{Timestamp: l.timestamp, Artist: s.artist(l.id)})
+			}
+			s.writeResult(w, artists)
+		case yamusic.LikePlaylist:
+			playlists := []yamusic.LikedPlaylist{}
+			for _, l := range likes {
+				var uid, kind int
+				fmt.Sscanf(l.id, "%d:%d", &uid, &kind)
+				liked := yamusic.LikedPlaylist{Timestamp: l.timestamp}
+				if owner, ok := s.users[uid]; ok {
+					if p, ok := owner.playlists[kind]; ok {
+						liked.Playlist = p.PlaylistsResult
+					}
+				}
+				playlists = append(playlists, liked)
+			}
+			s.writeResult(w, playlists)
+		default:
+			s.writeError(w, http.StatusNotFound, "not-found", "unknown type")
+		}
+	}
+}
+
+// changeLikes returns handler adding objects to list or removing them
+func (s *Server) changeLikes(list string) func(w http.ResponseWriter, r *http.Request, u *user) {
+	return func(w http.ResponseWriter, r *http.Request, u *user) {
+		if !s.own(w, r, u) {
+			return
+		}
+
+		typ := yamusic.LikeType(strings.TrimSuffix(r.PathValue("types"), "s"))
+		action := r.PathValue("action")
+		if action != "add-multiple" && action != "remove" {
+			s.writeError(w, http.StatusNotFound, "not-found", "unknown action")
+			return
+		}
+
+		ids := strings.Split(r.FormValue(string(typ)+"-ids"), ",")
+		if typ == yamusic.LikeTrack {
+			for _, id := range ids {
+				id, _, _ = strings.Cut(id, ":")
+				if _, ok := s.tracks[id]; !ok {
+					s.writeError(w, http.StatusBadRequest, "validate", "track "+id+" not found")
+					return
+				}
+			}
+		}
+
+		u.change(list, typ, action, ids)
+		if typ == yamusic.LikeTrack {
+			s.writeResult(w, yamusic.LibraryRevision{Revision: u.likesRevision})
+			return
+		}
+		s.writeResult(w, "ok")
+	}
+}
+
+// getRecommendations answers with all tracks that aren't in the playlist
+func (s *Server) getRecommendations(w http.ResponseWriter, r *http.Request, u *user) {
+	p, ok := s.playlist(w, r)
+	if !ok {
+		return
+	}
+
+	recommendations := new(yamusic.PlaylistsRecommendationsResp)
+	recommendations.Result.BatchID = fmt.Sprintf("%d.%d", p.Kind, p.Revision)
+	recommendations.Result.Tracks = []yamusic.Track{}
+	for _, t := range s.sortedTracks() {
+		if !slices.ContainsFunc(p.tracks, func(pt playlistTrack) bool { return pt.id == t.ID }) {
+			recommendations.Result.Tracks = append(recommendations.Result.Tracks, t.Track)
+		}
+	}
+	s.writeResult(w, recommendations.Result)
+}
+
+func (s *Server) getTracks(w http.ResponseWriter, r *http.Request, u *user) {
+	result := []yamusic.Track{}
+	for _, id := range strings.Split(r.FormValue("track-ids"), ",") {
+		id, _, _ = strings.Cut(id, ":")
+		if t, ok := s.tracks[id]; ok {
+			result = append(result, t.Track)
+		}
+	}
+	s.writeResult(w, result)
+}
+
+func (s *Server) getTrack(w http.ResponseWriter, r *http.Request, u *user) {
+	t, ok := s.track(w, r)
+	if !ok {
+		return
+	}
+	s.writeResult(w, []yamusic.Track{t.Track})
+}
+
+// getSimilarTracks answers with tracks sharing an artist with the track
+func (s *Server) getSimilarTracks(w http.ResponseWriter, r *http.Request, u *user) {
+	t, ok := s.track(w, r)
+	if !ok {
+		return
+	}
+
+	similar := new(yamusic.SimilarTracksResp)
+	similar.Result.Track = t.Track
+	similar.Result.SimilarTracks = []yamusic.Track{}
+	for _, other := range s.sortedTracks() {
+		if other.ID != t.ID && sharesArtist(other.Track, t.Track) {
+			similar.Result.SimilarTracks = append(similar.Result.SimilarTracks, other.Track)
+		}
+	}
+	s.writeResult(w, similar.Result)
+}
+
+func (s *Server) getSupplement(w http.ResponseWriter, r *http.Request, u *user) {
+	t, ok := s.track(w, r)
+	if !ok {
+		return
+	}
+
+	supplement := new(yamusic.Supplement)
+	supplement.Result.ID = t.ID
+	supplement.Result.Lyrics.FullLyrics = t.lyrics
+	supplement.Result.Lyrics.HasRights = t.lyrics != ""
+	s.writeResult(w, supplement.Result)
+}
+
+// getLyrics answers with link to lyrics of track if the request
+// is signed correctly
+func (s *Server) getLyrics(w http.ResponseWriter, r *http.Request, u *user) {
+	t, ok := s.track(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	mac := hmac.New(sha256.New, []byte(lyricsSignKey))
+	mac.Write([]byte(t.ID + query.Get("timeStamp")))
+	if query.Get("sign") != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		s.writeError(w, http.StatusBadRequest, "validate", "wrong sign")
+		return
+	}
+
+	format := yamusic.LyricsFormat(query.Get("format"))
+	if lyrics(t, format) == "" {
+		s.writeError(w, http.StatusNotFound, "not-found", "lyrics not found")
+		return
+	}
+
+	lyrics := new(yamusic.LyricsResp)
+	lyrics.Result.DownloadURL = s.URL + "/lyrics/" + t.ID + "/" + string(format)
+	s.writeResult(w, lyrics.Result)
+}
+
+func (s *Server) getDownloadInfo(w http.ResponseWriter, r *http.Request, u *user) {
+	t, ok := s.track(w, r)
+	if !ok {
+		return
+	}
+
+	info := new(yamusic.DownloadInfoResp)
+	info.Result = append(info.Result, struct {
+		Codec           string `json:"codec"`
+		Gain            bool   `json:"gain"`
+		Preview         bool   `json:"preview"`
+		DownloadInfoURL string `json:"downloadInfoUrl"`
+		Direct          bool   `json:"direct"`
+		BitrateInKbps   int    `json:"bitrateInKbps"`
+	}{
+		Codec:           "mp3",
+		DownloadInfoURL: s.URL + "/download-info/" + t.ID,
+		BitrateInKbps:   320,
+	})
+	s.writeResult(w, info.Result)
+}
+
+func (s *Server) getAlbum(w http.ResponseWriter, r *http.Request, u *user) {
+	album, ok := s.albumWithTracks(r.PathValue("id"))
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "not-found", "album not found")
+		return
+	}
+	s.writeResult(w, album.Album)
+}
+
+func (s *Server) getAlbumWithTracks(w http.ResponseWriter, r *http.Request, u *user) {
+	album, ok := s.albumWithTracks(r.PathValue("id"))
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "not-found", "album not found")
+		return
+	}
+	s.writeResult(w, album)
+}
+
+func (s *Server) getAlbums(w http.ResponseWriter, r *http.Request, u *user) {
+	result := yamusic.Albums{}
+	for _, id := range strings.Split(r.FormValue("album-ids"), ",") {
+		if album, ok := s.albumWithTracks(id); ok {
+			result = append(result, album.Album)
+		}
+	}
+	s.writeResult(w, result)
+}
+
+// albumWithTracks returns album by id collected from albums of tracks.
+// Tracks are split into volumes and ordered by their track positions.
+func (s *Server) albumWithTracks(id string) (yamusic.AlbumWithTracks, bool) {
+	albumID, err := strconv.Atoi(id)
+	if err != nil {
+		return yamusic.AlbumWithTracks{}, false
+	}
+
+	var album yamusic.AlbumWithTracks
+	found := false
+	for _, t := range s.tracks {
+		for _, a := range t.Albums {
+			if a.ID != albumID {
+				continue
+			}
+			if !found {
+				album.Album = a
+				found = true
+			}
+			volume := max(a.TrackPosition.Volume, 1)
+			for len(album.Volumes) < volume {
+				album.Volumes = append(album.Volumes, []yamusic.Track{})
+			}
+			album.Volumes[volume-1] = append(album.Volumes[volume-1], t.Track)
+		}
+	}
+	if !found {
+		return yamusic.AlbumWithTracks{}, false
+	}
+
+	album.TrackCount = 0
+	for i, volume := range album.Volumes {
+		slices.SortFunc(volume, func(a, b yamusic.Track) int {
+			return position(a, albumID) - position(b, albumID)
+		})
+		album.Volumes[i] = volume
+		album.TrackCount += len(volume)
+	}
+	album.TrackPosition.Volume = 0
+	album.TrackPosition.Index = 0
+	return album, true
+}
+
+// downloadInfo answers with XML describing location of track's file
+// in the storage
+func (s *Server) downloadInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.tracks[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	host := strings.TrimPrefix(s.URL, "https://")
+	info := yamusic.DownloadInfo{
+		Host:   host,
+		Path:   "/" + r.PathValue("id"),
+		TS:     strconv.FormatInt(time.Now().Unix(), 16),
+		Region: "-1",
+		S:      secret(r.PathValue("id")),
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprint(w, xml.Header)
+	xml.NewEncoder(w).Encode(info)
+}
+
+// storage answers with file of track if the link is signed correctly
+func (s *Server) storage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	sign := md5.Sum([]byte(signPrefix + id + secret(id)))
+	if r.PathValue("sign") != hex.EncodeToString(sign[:]) {
+		http.Error(w, "wrong sign", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	t, ok := s.tracks[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "audio/mpeg")
+	w.Write(t.file)
+}
+
+// lyricsStorage answers with lyrics of track in format
+func (s *Server) lyricsStorage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tracks[r.PathValue("id")]
+	if !ok || lyrics(t, yamusic.LyricsFormat(r.PathValue("format"))) == "" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, lyrics(t, yamusic.LyricsFormat(r.PathValue("format"))))
+}
+
+// apply returns tracks with diff applied
+func (s *Server) apply(tracks []playlistTrack, diff []diffOp) ([]playlistTrack, error) {
+	tracks = slices.Clone(tracks)
+	for _, op := range diff {
+		switch op.Op {
+		case "insert":
+			if op.At < 0 || op.At > len(tracks) {
+				return nil, fmt.Errorf("insert at %d out of range", op.At)
+			}
+			inserted := make([]playlistTrack, 0, len(op.Tracks))
+			for _, t := range op.Tracks {
+				if _, ok := s.tracks[strconv.Itoa(t.ID)]; !ok {
+					return nil, fmt.Errorf("track %d not found", t.ID)
+				}
+				inserted = append(inserted, playlistTrack{
+					id:        strconv.Itoa(t.ID),
+					albumID:   t.AlbumID,
+					timestamp: time.Now(),
+				})
+			}
+			tracks = slices.Insert(tracks, op.At, inserted...)
+		case "delete":
+			if op.From < 0 || op.From > op.To || op.To > len(tracks) {
+				return nil, fmt.Errorf("delete from %d to %d out of range", op.From, op.To)
+			}
+			if len(op.Tracks) != op.To-op.From {
+				return nil, fmt.Errorf("delete from %d to %d of %d tracks", op.From, op.To, len(op.Tracks))
+			}
+			for i, t := range op.Tracks {
+				if tracks[op.From+i].id != strconv.Itoa(t.ID) {
+					return nil, fmt.Errorf("track %d is not at %d", t.ID, op.From+i)
+				}
+			}
+			tracks = slices.Delete(tracks, op.From, op.To)
+		default:
+			return nil, fmt.Errorf("unknown op %q", op.Op)
+		}
+	}
+	return tracks, nil
+}
+
+// owner returns user by uid from path or answers 404
+func (s *Server) owner(w http.ResponseWriter, r *http.Request) (*user, bool) {
+	uid, err := strconv.Atoi(r.PathValue("uid"))
+	u, ok := s.users[uid]
+	if err != nil || !ok {
+		s.writeError(w, http.StatusNotFound, "not-found", "user not found")
+		return nil, false
+	}
+	return u, true
+}
+
+// own reports whether uid from path is the authorized user,
+// otherwise answers 403
+func (s *Server) own(w http.ResponseWriter, r *http.Request, u *user) bool {
+	if r.PathValue("uid") != strconv.Itoa(u.uid) {
+		s.writeError(w, http.StatusForbidden, "forbidden", "library of another user")
+		return false
+	}
+	return true
+}
+
+// playlist returns playlist by uid and kind from path or answers 404
+func (s *Server) playlist(w http.ResponseWriter, r *http.Request) (*playlist, bool) {
+	owner, ok := s.owner(w, r)
+	if !ok {
+		return nil, false
+	}
+	kind, err := strconv.Atoi(r.PathValue("kind"))
+	p, ok := owner.playlists[kind]
+	if err != nil || !ok {
+		s.writeError(w, http.StatusNotFound, "not-found", "playlist not found")
+		return nil, false
+	}
+	return p, true
+}
+
+// track returns track by id from path or answers 404
+func (s *Server) track(w http.ResponseWriter, r *http.Request) (*track, bool) {
+	t, ok := s.tracks[r.PathValue("id")]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "not-found", "track not found")
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *Server) playlistWithTracks(p *playlist) yamusic.PlaylistWithTracks {
+	result := yamusic.PlaylistWithTracks{
+		PlaylistsResult: p.PlaylistsResult,
+		Tracks:          yamusic.Tracks{},
+	}
+	for _, t := range p.tracks {
+		id, _ := strconv.Atoi(t.id)
+		result.Tracks = append(result.Tracks, yamusic.TrackFull{
+			ID:        id,
+			Timestamp: t.timestamp,
+			Track:     s.tracks[t.id].Track,
+		})
+	}
+	return result
+}
+
+func (s *Server) newPlaylistTrack(id string) playlistTrack {
+	t := s.mustTrack(id)
+	pt := playlistTrack{id: id, timestamp: time.Now()}
+	if len(t.Albums) > 0 {
+		pt.albumID = t.Albums[0].ID
+	}
+	return pt
+}
+
+// touch updates computed fields of playlist
+func (s *Server) touch(p *playlist) {
+	p.TrackCount = len(p.tracks)
+	p.DurationMs = 0
+	for _, t := range p.tracks {
+		p.DurationMs += s.tracks[t.id].DurationMs
+	}
+	p.Modified = time.Now()
+}
+
+func (s *Server) mustUser(uid int) *user {
+	u, ok := s.users[uid]
+	if !ok {
+		panic(fmt.Sprintf("yamusictest: unknown user %d", uid))
+	}
+	return u
+}
+
+func (s *Server) mustTrack(id string) *track {
+	t, ok := s.tracks[id]
+	if !ok {
+		panic(fmt.Sprintf("yamusictest: unknown track %s", id))
+	}
+	return t
+}
+
+func (s *Server) mustPlaylist(uid, kind int) *playlist {
+	p, ok := s.mustUser(uid).playlists[kind]
+	if !ok {
+		panic(fmt.Sprintf("yamusictest: unknown playlist %d of user %d", kind, uid))
+	}
+	return p
+}
+
+func (s *Server) writeResult(w http.ResponseWriter, result any) {
+	s.write(w, http.StatusOK, envelope{Result: result})
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, name, message string) {
+	s.write(w, status, envelope{Error: &yamusic.Error{Name: name, Message: message}})
+}
+
+func (s *Server) write(w http.ResponseWriter, status int, body envelope) {
+	s.reqID++
+	body.InvocationInfo = yamusic.InvocationInfo{
+		Hostname: "yamusictest",
+		ReqID:    fmt.Sprintf("yamusictest-%d", s.reqID),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (u *user) newPlaylist(title, visibility string) *playlist {
+	u.nextKind++
+	p := &playlist{PlaylistsResult: yamusic.PlaylistsResult{
+		UID:        u.uid,
+		Kind:       u.nextKind,
+		Revision:   1,
+		Title:      title,
+		Visibility: visibility,
+		Available:  true,
+		Created:    time.Now(),
+		Owner:      yamusic.PlaylistsOwner{UID: u.uid, Login: u.login},
+	}}
+	u.playlists[p.Kind] = p
+	return p
+}
+
+// change adds objects by ids to list of type or removes them from it.
+// Liked objects are removed from disliked ones and vice versa.
+func (u *user) change(list string, typ yamusic.LikeType, action string, ids []string) {
+	opposite := "dislikes"
+	if list == "dislikes" {
+		opposite = "likes"
+	}
+
+	for _, id := range ids {
+		// Track id may be given with album id as trackID:albumID
+		if typ == yamusic.LikeTrack {
+			id, _, _ = strings.Cut(id, ":")
+		}
+		u.remove(list+"/"+string(typ), id)
+		if action == "add-multiple" {
+			u.remove(opposite+"/"+string(typ), id)
+			key := list + "/" + string(typ)
+			u.likes[key] = append([]like{{id: id, timestamp: time.Now()}}, u.likes[key]...)
+		}
+	}
+	if typ == yamusic.LikeTrack {
+		u.likesRevision++
+	}
+}
+
+func (u *user) remove(key, id string) {
+	u.likes[key] = slices.DeleteFunc(u.likes[key], func(l like) bool { return l.id == id })
+}
+
+func sortedKinds(u *user) []int {
+	kinds := make([]int, 0, len(u.playlists))
+	for kind := range u.playlists {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// sortedTracks returns tracks ordered by id
+func (s *Server) sortedTracks() []*track {
+	tracks := make([]*track, 0, len(s.tracks))
+	for _, t := range s.tracks {
+		tracks = append(tracks, t)
+	}
+	slices.SortFunc(tracks, func(a, b *track) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return tracks
+}
+
+// sharesArtist reports whether tracks have a common artist
+func sharesArtist(a, b yamusic.Track) bool {
+	for _, artist := range a.Artists {
+		if slices.ContainsFunc(b.Artists, func(other yamusic.Artist) bool { return other.ID == artist.ID }) {
+			return true
+		}
+	}
+	return false
+}
+
+// artist returns artist by id from artists of tracks
+func (s *Server) artist(id string) yamusic.Artist {
+	for _, t := range s.tracks {
+		for _, a := range t.Artists {
+			if strconv.Itoa(a.ID) == id {
+				return a
+			}
+		}
+	}
+	return yamusic.Artist{}
+}
+
+// position returns index of track in album by id
+func position(t yamusic.Track, albumID int) int {
+	for _, a := range t.Albums {
+		if a.ID == albumID {
+			return a.TrackPosition.Index
+		}
+	}
+	return 0
+}
+
+// secret returns secret part of storage link of track
+// lyrics returns lyrics of track in format
+func lyrics(t *track, format yamusic.LyricsFormat) string {
+	if format == yamusic.LyricsLRC {
+		return t.syncLyrics
+	}
+	return t.lyrics
+}
+
+func secret(id string) string {
+	sum := md5.Sum([]byte("yamusictest" + id))
+	return hex.EncodeToString(sum[:8])
+}
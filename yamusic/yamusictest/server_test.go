@@ -0,0 +1,542 @@
+package yamusictest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"awesome/yamusic"
+	"awesome/yamusic/yamusictest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const uid = 42
+
+func newTrack(id, albumID int, title, artist string) yamusic.Track {
+	return yamusic.Track{
+		ID:         fmt.Sprint(id),
+		Title:      title,
+		DurationMs: 1000,
+		Artists:    yamusic.Artists{{ID: id * 10, Name: artist}},
+		Albums:     yamusic.Albums{{ID: albumID, Title: title}},
+	}
+}
+
+func setup(t *testing.T) *yamusictest.Server {
+	server := yamusictest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddUser(uid, "user", "token")
+	server.AddUser(7, "friend", "friend-token")
+	server.AddTrack(newTrack(1, 10, "Uprising", "Muse"), []byte("mp3 of 1"))
+	server.AddTrack(newTrack(2, 20, "Believer", "Imagine Dragons"), []byte("mp3 of 2"))
+	server.AddTrack(newTrack(3, 30, "Sonne", "Rammstein"), []byte("mp3 of 3"))
+
+	return server
+}
+
+func TestServer_Playlists(t *testing.T) {
+	server := setup(t)
+	client := server.NewClient(uid)
+	ctx := context.Background()
+
+	created, _, err := client.Playlists().Create(ctx, "Rock", true)
+	assert.NoError(t, err)
+	kind := created.Result.Kind
+	assert.Equal(t, 1, created.Result.Revision)
+
+	_, _, err = client.Playlists().AddTracks(ctx, kind, 1, []yamusic.PlaylistsTrack{
+		{ID: 1, AlbumID: 10},
+		{ID: 2, AlbumID: 20},
+	}, nil)
+	assert.NoError(t, err)
+
+	renamed, _, err := client.Playlists().Rename(ctx, kind, "Rock & Roll")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, renamed.Result.Revision)
+
+	playlist, _, err := client.Playlists().Get(ctx, 0, kind)
+	assert.NoError(t, err)
+	assert.Equal(t, "Rock & Roll", playlist.Result.Title)
+	assert.Equal(t, 2, playlist.Result.TrackCount)
+	if assert.Len(t, playlist.Result.Tracks, 2) {
+		assert.Equal(t, "Uprising", playlist.Result.Tracks[0].Track.Title)
+	}
+
+	byKinds, _, err := client.Playlists().GetByKinds(ctx, 0, &yamusic.PlaylistsGetByKindOptions{Kinds: []int{kind}})
+	assert.NoError(t, err)
+	if assert.Len(t, byKinds.Result, 1) && assert.Len(t, byKinds.Result[0].Tracks, 2) {
+		assert.Equal(t, 20, byKinds.Result[0].Tracks[1].AlbumID)
+	}
+
+	_, _, err = client.Playlists().Delete(ctx, kind)
+	assert.NoError(t, err)
+	_, _, err = client.Playlists().Get(ctx, 0, kind)
+	assert.ErrorIs(t, err, yamusic.ErrNotFound)
+}
+
+func TestServer_RevisionConflict(t *testing.T) {
+	server := setup(t)
+	client := server.NewClient(uid)
+	ctx := context.Background()
+	kind := server.AddPlaylist(uid, "Rock", "1")
+
+	playlist, _, err := client.Playlists().Get(ctx, 0, kind)
+	assert.NoError(t, err)
+
+	// The playlist is changed by another client in between
+	server.AddPlaylistTracks(uid, kind, "2")
+
+	tracks := []yamusic.PlaylistsTrack{{ID: 3, AlbumID: 30}}
+	_, _, err = client.Playlists().AddTracks(ctx, kind, playlist.Result.Revision, tracks, nil)
+	assert.ErrorIs(t, err, yamusic.ErrRevisionConflict)
+
+	playlist, _, err = client.Playlists().Get(ctx, 0, kind)
+	assert.NoError(t, err)
+	_, _, err = client.Playlists().AddTracks(ctx, kind, playlist.Result.Revision, tracks, nil)
+	assert.NoError(t, err)
+
+	got, ok := server.Playlist(uid, kind)
+	assert.True(t, ok)
+	assert.Equal(t, 3, got.Revision)
+	assert.Equal(t, 3, got.TrackCount)
+}
+
+func TestServer_RemoveTracksValidatesDiff(t *testing.T) {
+	server := setup(t)
+	client := server.NewClient(uid)
+	ctx := context.Background()
+	kind := server.AddPlaylist(uid, "Rock", "1", "2")
+
+	// Track 2 is not the first one
+	_, _, err := client.Playlists().RemoveTracks(ctx, kind, 1, []yamusic.PlaylistsTrack{{ID: 2, AlbumID: 20}}, nil)
+	var apiErr *yamusic.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "validate", apiErr.Name)
+	}
+
+	_, _, err = client.Playlists().RemoveTracks(
+		ctx, kind, 1,
+		[]yamusic.PlaylistsTrack{{ID: 2, AlbumID: 20}},
+		&yamusic.PlaylistsRemoveTracksOptions{From: 1, To: 2},
+	)
+	assert.NoError(t, err)
+
+	got, _ := server.Playlist(uid, kind)
+	if assert.Len(t, got.Tracks, 1) {
+		assert.Equal(t, "1", got.Tracks[0].Track.ID)
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	server := setup(t)
+	baseURL, _ := url.Parse(server.URL + "/")
+	client := yamusic.NewClient(
+		yamusic.BaseURL(baseURL),
+		yamusic.HTTPClient(server.Client()),
+		yamusic.OAuthToken("stale"),
+	)
+
+	_, _, err := client.Playlists().List(context.Background(), 0)
+	assert.ErrorIs(t, err, yamusic.ErrUnauthorized)
+}
+
+func TestServer_PrivatePlaylists(t *testing.T) {
+	server := setup(t)
+	ctx := context.Background()
+
+	owner := server.NewClient(uid)
+	created, _, err := owner.Playlists().Create(ctx, "Secret", false)
+	assert.NoError(t, err)
+
+	friend := server.NewClient(7)
+	list, _, err := friend.Playlists().List(ctx, uid)
+	assert.NoError(t, err)
+	assert.Empty(t, list.Result)
+
+	_, _, err = friend.Playlists().Get(ctx, uid, created.Result.Kind)
+	assert.ErrorIs(t, err, yamusic.ErrNotFound)
+}
+
+func TestServer_DistributeTracksByPlaylists(t *testing.T) {
+	server := setup(t)
+	rock := server.AddPlaylist(uid, "Rock", "3")
+	alternative := server.AddPlaylist(uid, "Alternative")
+	server.Like(uid, "1", "2", "3")
+
+	dir := t.TempDir()
+	playlistsMap := fmt.Sprintf(`playlists:
+  - title: Rock
+    kind: %d
+    authors: [Rammstein]
+  - title: Alternative
+    kind: %d
+    authors: [Muse, Imagine Dragons]
+`, rock, alternative)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "playlists_map.yaml"), []byte(playlistsMap), 0o644))
+	t.Chdir(dir)
+
+	client := server.NewClient(uid)
+	added, err := client.Playlists().DistributeTracksByPlaylists(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{alternative: 2}, added)
+
+	got, _ := server.Playlist(uid, alternative)
+	assert.Equal(t, 2, got.Revision)
+	assert.Equal(t, 2, got.TrackCount)
+
+	got, _ = server.Playlist(uid, rock)
+	assert.Equal(t, 1, got.Revision)
+}
+
+func TestServer_DownloadAll(t *testing.T) {
+	server := setup(t)
+	server.SetLyrics("1", "They will not control us")
+	kind := server.AddPlaylist(uid, "Rock", "1", "2")
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := fmt.Sprintf("output: %s\nlog: %s\n", dir, filepath.Join(dir, "log"))
+	assert.NoError(t, os.WriteFile(configPath, []byte(config), 0o644))
+
+	client := server.NewClient(uid, yamusic.NewConfig(configPath))
+	result, err := client.Playlists().DownloadAll(context.Background(), []int{kind})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Downloaded)
+
+	b, err := os.ReadFile(filepath.Join(dir, "Rock", "tracks", "Muse - Uprising.mp3"))
+	assert.NoError(t, err)
+	assert.Equal(t, "mp3 of 1", string(b))
+
+	b, err = os.ReadFile(filepath.Join(dir, "Rock", "lyrics", "Muse - Uprising.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "They will not control us\n", string(b))
+}
+
+func TestServer_DownloadAlbum(t *testing.T) {
+	server := setup(t)
+	for i, title := range []string{"Madness", "Survival"} {
+		track := newTrack(101+i, 100, title, "Muse")
+		track.Albums[0].Title = "The 2nd Law"
+		track.Albums[0].Artists = yamusic.Artists{{Name: "Muse"}}
+		track.Albums[0].TrackPosition.Volume = 1
+		track.Albums[0].TrackPosition.Index = 2 - i
+		server.AddTrack(track, []byte(title))
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := fmt.Sprintf("output: %s\nlog: %s\n", dir, filepath.Join(dir, "log"))
+	assert.NoError(t, os.WriteFile(configPath, []byte(config), 0o644))
+	client := server.NewClient(uid, yamusic.NewConfig(configPath))
+
+	album, _, err := client.Albums().GetWithTracks(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, album.Result.TrackCount)
+	if assert.Len(t, album.Result.Tracks(), 2) {
+		assert.Equal(t, "Survival", album.Result.Tracks()[0].Title)
+	}
+
+	result, err := client.Albums().DownloadOne(context.Background(), 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Downloaded)
+	b, err := os.ReadFile(filepath.Join(dir, "Muse - The 2nd Law", "tracks", "Muse - Madness.mp3"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Madness", string(b))
+}
+
+func TestServer_Likes(t *testing.T) {
+	server := setup(t)
+	client := server.NewClient(uid)
+	ctx := context.Background()
+	friendPlaylist := server.AddPlaylist(7, "Friend's mix", "1")
+
+	_, _, err := client.Likes().Dislike(ctx, yamusic.LikeTrack, []string{"2"})
+	assert.NoError(t, err)
+	liked, _, err := client.Likes().Like(ctx, yamusic.LikeTrack, []string{"1:10", "2:20"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, liked.Result.Revision)
+	assert.Equal(t, []string{"2", "1"}, server.Likes(uid, yamusic.LikeTrack))
+	assert.Empty(t, server.Dislikes(uid, yamusic.LikeTrack))
+
+	_, _, err = client.Likes().Like(ctx, yamusic.LikeAlbum, []string{"30"})
+	assert.NoError(t, err)
+	_, _, err = client.Likes().Like(ctx, yamusic.LikeArtist, []string{"10"})
+	assert.NoError(t, err)
+	_, _, err = client.Likes().Like(ctx, yamusic.LikePlaylist, []string{yamusic.PlaylistLikeID(7, friendPlaylist)})
+	assert.NoError(t, err)
+
+	albums, _, err := client.Likes().GetAlbums(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, albums.Result, 1) {
+		assert.Equal(t, "Sonne", albums.Result[0].Album.Title)
+	}
+	artists, _, err := client.Likes().GetArtists(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, artists.Result, 1) {
+		assert.Equal(t, "Muse", artists.Result[0].Artist.Name)
+	}
+	playlists, _, err := client.Likes().GetPlaylists(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, playlists.Result, 1) {
+		assert.Equal(t, "Friend's mix", playlists.Result[0].Playlist.Title)
+	}
+
+	diff, snapshot, err := client.Likes().SyncTracks(ctx, nil)
+	assert.NoError(t, err)
+	assert.Len(t, diff.Added, 2)
+
+	_, _, err = client.Likes().Unlike(ctx, yamusic.LikeTrack, []string{"1"})
+	assert.NoError(t, err)
+	diff, _, err = client.Likes().SyncTracks(ctx, snapshot)
+	assert.NoError(t, err)
+	assert.Empty(t, diff.Added)
+	if assert.Len(t, diff.Removed, 1) {
+		assert.Equal(t, "1", diff.Removed[0].ID)
+	}
+
+	_, _, err = client.Likes().Like(ctx, yamusic.LikeTrack, []string{"404"})
+	assert.Error(t, err)
+}
+
+func TestServer_Rotor(t *testing.T) {
+	server := setup(t)
+	server.AddStation(yamusic.MyWave, "My Wave", "1", "2", "3")
+	client := server.NewClient(uid)
+	ctx := context.Background()
+
+	dashboard, _, err := client.Rotor().GetDashboard(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, dashboard.Result.Stations, 1) {
+		assert.Equal(t, "My Wave", dashboard.Result.Stations[0].Station.Name)
+	}
+
+	settings := yamusic.StationSettings{Language: "russian", MoodEnergy: "calm", Diversity: "discover"}
+	_, _, err = client.Rotor().SetSettings(ctx, yamusic.MyWave, settings)
+	assert.NoError(t, err)
+	info, _, err := client.Rotor().GetInfo(ctx, yamusic.MyWave)
+	assert.NoError(t, err)
+	if assert.Len(t, info.Result, 1) {
+		assert.Equal(t, settings, info.Result[0].Settings2)
+	}
+
+	tracks, _, err := client.Rotor().GetTracks(ctx, yamusic.MyWave, &yamusic.RotorTracksOptions{Queue: "2:20"})
+	assert.NoError(t, err)
+	if assert.Len(t, tracks.Result.Sequence, 3) {
+		assert.Equal(t, "3", tracks.Result.Sequence[0].Track.ID)
+	}
+	batchID := tracks.Result.BatchID
+	track := tracks.Result.Sequence[0].Track
+
+	_, _, err = client.Rotor().RadioStarted(ctx, yamusic.MyWave, "desktop-radio", batchID)
+	assert.NoError(t, err)
+	_, _, err = client.Rotor().TrackStarted(ctx, yamusic.MyWave, track, batchID)
+	assert.NoError(t, err)
+	_, _, err = client.Rotor().TrackFinished(ctx, yamusic.MyWave, track, time.Second, batchID)
+	assert.NoError(t, err)
+
+	feedback := server.Feedback(uid, yamusic.MyWave)
+	if assert.Len(t, feedback, 3) {
+		assert.Equal(t, yamusic.RotorRadioStarted, feedback[0].Type)
+		assert.Equal(t, "3:30", feedback[2].TrackID)
+		assert.Equal(t, 1.0, feedback[2].TotalPlayedSeconds)
+		assert.Equal(t, batchID, feedback[2].BatchID)
+	}
+
+	_, _, err = client.Rotor().GetInfo(ctx, yamusic.StationID{Type: "genre", Tag: "jazz"})
+	assert.ErrorIs(t, err, yamusic.ErrNotFound)
+}
+
+func TestServer_Queues(t *testing.T) {
+	server := setup(t)
+	phone := yamusic.Queue{
+		Context: yamusic.QueueContext{Type: "album", ID: "10"},
+		Tracks: []yamusic.QueueTrack{
+			{TrackID: "1", AlbumID: "10"},
+			{TrackID: "2", AlbumID: "20"},
+			{TrackID: "3", AlbumID: "30"},
+		},
+		CurrentIndex: 1,
+	}
+	server.AddQueue(uid, yamusic.Queue{Context: yamusic.QueueContext{Type: "radio"}})
+	phoneID := server.AddQueue(uid, phone)
+	client := server.NewClient(uid, yamusic.Device("os=Linux; device_id=home"))
+	ctx := context.Background()
+
+	latest, err := client.Queues().Latest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, phoneID, latest.ID)
+	current, _ := latest.CurrentTrack()
+	assert.Equal(t, "2", current.TrackID)
+
+	_, _, err = client.Queues().UpdatePosition(ctx, phoneID, 2, false)
+	assert.NoError(t, err)
+	got, _ := server.Queue(uid, phoneID)
+	assert.Equal(t, 2, got.CurrentIndex)
+
+	_, _, err = client.Queues().UpdatePosition(ctx, phoneID, 3, false)
+	assert.Error(t, err)
+
+	created, _, err := client.Queues().Create(ctx, phone)
+	assert.NoError(t, err)
+	latest, err = client.Queues().Latest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, created.Result.ID, latest.ID)
+	assert.Len(t, latest.Tracks, 3)
+}
+
+func TestServer_ExtendPlaylist(t *testing.T) {
+	server := setup(t)
+	muse := yamusic.Artists{{ID: 10, Name: "Muse"}}
+	server.AddTrack(yamusic.Track{ID: "4", Title: "Hysteria", Artists: muse, Albums: yamusic.Albums{{ID: 40}}}, nil)
+	server.AddTrack(yamusic.Track{ID: "5", Title: "Starlight", Artists: muse, Albums: yamusic.Albums{{ID: 50}}}, nil)
+	kind := server.AddPlaylist(uid, "Muse", "1")
+	client := server.NewClient(uid)
+	ctx := context.Background()
+
+	similar, _, err := client.Tracks().GetSimilar(ctx, "1")
+	assert.NoError(t, err)
+	assert.Len(t, similar.Result.SimilarTracks, 2)
+
+	added, err := client.Playlists().Extend(ctx, kind, 5)
+	assert.NoError(t, err)
+	assert.Len(t, added, 2)
+
+	playlist, _ := server.Playlist(uid, kind)
+	var ids []string
+	for _, track := range playlist.Tracks {
+		ids = append(ids, track.Track.ID)
+	}
+	assert.Equal(t, []string{"4", "5", "1"}, ids)
+}
+
+func TestServer_DownloadSyncLyrics(t *testing.T) {
+	server := setup(t)
+	server.SetSyncLyrics("1", "[00:01.00]Paralysed\n[00:03.00]They will not control us")
+	server.SetLyrics("2", "Shooting stars never stop")
+	server.SetSyncLyrics("3", "[00:01.00]Sonne")
+	kind := server.AddPlaylist(uid, "Rock", "1", "2", "3")
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	config := fmt.Sprintf("output: %s\nlog: %s\n", dir, filepath.Join(dir, "log"))
+	assert.NoError(t, os.WriteFile(configPath, []byte(config), 0o644))
+
+	client := server.NewClient(uid, yamusic.NewConfig(configPath), yamusic.Lyrics(&yamusic.LyricsOptions{Embed: true}))
+	result, err := client.Playlists().DownloadAll(context.Background(), []int{kind})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Downloaded)
+
+	tracks := filepath.Join(dir, "Rock", "tracks")
+	b, err := os.ReadFile(filepath.Join(tracks, "Muse - Uprising.lrc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[00:01.00]Paralysed\n[00:03.00]They will not control us\n", string(b))
+
+	b, err = os.ReadFile(filepath.Join(tracks, "Muse - Uprising.mp3"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "ID3\x03"))
+	assert.True(t, strings.HasSuffix(string(b), "mp3 of 1"))
+
+	b, err = os.ReadFile(filepath.Join(dir, "Rock", "lyrics", "Imagine Dragons - Believer.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Shooting stars never stop\n", string(b))
+
+	// Synced lyrics next to tracks don't count as downloaded tracks
+	result, err = client.Playlists().DownloadAll(context.Background(), []int{kind})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Skipped)
+}
+
+func TestServer_EditPlaylist(t *testing.T) {
+	server := setup(t)
+	kind := server.AddPlaylist(uid, "Rock", "1")
+	client := server.NewClient(uid)
+	friend := server.NewClient(7)
+	ctx := context.Background()
+
+	described, _, err := client.Playlists().SetDescription(ctx, kind, "Loud songs")
+	assert.NoError(t, err)
+	assert.Equal(t, "Loud songs", described.Result.Description)
+
+	_, _, err = client.Playlists().SetVisibility(ctx, kind, false)
+	assert.NoError(t, err)
+	_, _, err = friend.Playlists().Get(ctx, uid, kind)
+	assert.ErrorIs(t, err, yamusic.ErrNotFound)
+
+	public, _, err := client.Playlists().SetVisibility(ctx, kind, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "public", public.Result.Visibility)
+	playlist, _, err := friend.Playlists().Get(ctx, uid, kind)
+	assert.NoError(t, err)
+	assert.Equal(t, "Loud songs", playlist.Result.Description)
+
+	png := []byte("\x89PNG\r\n\x1a\ncover")
+	uploaded, _, err := client.Playlists().UploadCover(ctx, kind, bytes.NewReader(png))
+	assert.NoError(t, err)
+	assert.True(t, uploaded.Result.Cover.Custom)
+	assert.Equal(t, png, server.PlaylistCover(uid, kind))
+
+	_, _, err = client.Playlists().UploadCover(ctx, kind, strings.NewReader("not an image"))
+	assert.Error(t, err)
+
+	cleared, _, err := client.Playlists().ClearCover(ctx, kind)
+	assert.NoError(t, err)
+	assert.False(t, cleared.Result.Cover.Custom)
+	assert.Nil(t, server.PlaylistCover(uid, kind))
+	assert.Equal(t, described.Result.Revision+4, cleared.Result.Revision)
+}
+
+func TestServer_Plays(t *testing.T) {
+	server := setup(t)
+	kind := server.AddPlaylist(uid, "Rock", "1", "2")
+	outbox := &yamusic.PlaysOptions{Dir: t.TempDir()}
+	ctx := context.Background()
+
+	// Plays recorded while the API is unreachable are reported later
+	down := yamusictest.NewServer()
+	down.AddUser(uid, "user", "token")
+	offline := down.NewClient(uid, yamusic.Plays(outbox))
+	down.Close()
+	err := offline.Plays().Record(ctx, yamusic.Play{
+		TrackID:            "1",
+		AlbumID:            "10",
+		PlaylistID:         yamusic.PlaylistPlayID(uid, kind),
+		From:               "playlist",
+		EndPositionSeconds: 1,
+		TotalPlayedSeconds: 1,
+		TrackLengthSeconds: 1,
+	})
+	assert.Error(t, err)
+	assert.Empty(t, server.Plays(uid))
+
+	client := server.NewClient(uid, yamusic.Plays(outbox))
+	err = client.Plays().Record(ctx, yamusic.Play{TrackID: "2", AlbumID: "20"})
+	assert.NoError(t, err)
+
+	plays := server.Plays(uid)
+	if assert.Len(t, plays, 2) {
+		assert.Equal(t, "1", plays[0].TrackID)
+		assert.Equal(t, "42:1001", plays[0].PlaylistID)
+		assert.Equal(t, "2", plays[1].TrackID)
+	}
+
+	// Reporting the same play again doesn't count it twice
+	_, _, err = client.Plays().Report(ctx, plays...)
+	assert.NoError(t, err)
+	assert.Len(t, server.Plays(uid), 2)
+
+	pending, err := client.Plays().Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}